@@ -0,0 +1,199 @@
+package envar
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/neumachen/gobag"
+)
+
+// stringValidator returns a TypedValidatorFunc that checks the value with the
+// check func. The value of a string field, each element of a slice of strings,
+// or the env value of a field of another type, split for a slice, is checked.
+// The message of the ValidationError states that the value is not the desc.
+func stringValidator(desc string, check func(v string) bool) TypedValidatorFunc {
+	return func(parserCtx ParserCtxAccessor, parsedField ParsedFieldGetter, value reflect.Value, _ string) error {
+		for _, v := range validatedStrings(parserCtx, parsedField, value) {
+			if !check(v) {
+				parserCtx.AddFieldValidationError(NewValidationError(
+					parsedField,
+					fmt.Sprintf(
+						"env key: %s value %s is not %s", parsedField.GetEnvKey(), redactElem(parsedField, v), desc,
+					),
+				))
+				return nil
+			}
+		}
+		return nil
+	}
+}
+
+// validatedStrings returns the strings that are checked by a stringValidator.
+func validatedStrings(parserCtx ParserCtxGetter, parsedField ParsedFieldGetter, value reflect.Value) []string {
+	if value.Kind() == reflect.String {
+		return []string{value.String()}
+	}
+	if value.Kind() != reflect.Slice || isSingleValueSlice(parserCtx, value.Type()) {
+		return []string{parsedField.GetFieldValue()}
+	}
+
+	elemType := value.Type().Elem()
+	if elemType.Kind() == reflect.Ptr {
+		elemType = elemType.Elem()
+	}
+	if elemType.Kind() == reflect.String {
+		values := make([]string, value.Len())
+		for i := range values {
+			values[i] = reflect.Indirect(value.Index(i)).String()
+		}
+		return values
+	}
+
+	// the same delimiters as the ones used to set the slice
+	delim := parserCtx.GetEnvSliceDelim()
+	if gobag.StringIsEmpty(parsedField.GetEnvValue()) {
+		delim = defaultDelim
+	}
+	return strings.Split(parsedField.GetFieldValue(), delim)
+}
+
+var builtinValidatorsFunc = TypedValidatorFuncsMap{
+	"url":          stringValidator("a valid URL", isURL),
+	"http_url":     stringValidator("a valid HTTP URL", isHTTPURL),
+	"hostport":     stringValidator("a valid host:port", isHostPort),
+	"port":         stringValidator("a valid port", isPort),
+	"ip":           stringValidator("a valid IP address", isIP),
+	"ipv4":         stringValidator("a valid IPv4 address", isIPv4),
+	"ipv6":         stringValidator("a valid IPv6 address", isIPv6),
+	"cidr":         stringValidator("a valid CIDR", isCIDR),
+	"email":        stringValidator("a valid email address", isEmail),
+	"file_exists":  stringValidator("an existing file", isFile),
+	"dir_exists":   stringValidator("an existing directory", isDir),
+	"writable_dir": stringValidator("a writable directory", isWritableDir),
+	"abs_path":     stringValidator("an absolute path", filepath.IsAbs),
+	"uuid":         stringValidator("a valid UUID", uuidRegexp.MatchString),
+	"base64":       stringValidator("valid base64", isBase64),
+	"hex":          stringValidator("valid hex", isHex),
+	"lowercase":    stringValidator("lowercase", isLowercase),
+	"printable":    stringValidator("printable", isPrintable),
+}
+
+// isURL reports whether v is an absolute URL, e.g. postgres://host/db or
+// mailto:user@example.com
+func isURL(v string) bool {
+	u, err := url.Parse(v)
+	if err != nil {
+		return false
+	}
+	return u.Scheme != "" && (u.Host != "" || u.Opaque != "" || u.Path != "")
+}
+
+func isHTTPURL(v string) bool {
+	u, err := url.Parse(v)
+	if err != nil {
+		return false
+	}
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// isHostPort reports whether v is a host and a port, the host can be empty,
+// e.g. :8080
+func isHostPort(v string) bool {
+	_, port, err := net.SplitHostPort(v)
+	if err != nil {
+		return false
+	}
+	return isPort(port)
+}
+
+func isPort(v string) bool {
+	port, err := strconv.ParseUint(v, 10, 16)
+	return err == nil && port > 0
+}
+
+func isIP(v string) bool {
+	return net.ParseIP(v) != nil
+}
+
+func isIPv4(v string) bool {
+	return net.ParseIP(v) != nil && !strings.Contains(v, ":")
+}
+
+func isIPv6(v string) bool {
+	return net.ParseIP(v) != nil && strings.Contains(v, ":")
+}
+
+func isCIDR(v string) bool {
+	_, _, err := net.ParseCIDR(v)
+	return err == nil
+}
+
+// isEmail reports whether v is a bare email address, without a display name.
+func isEmail(v string) bool {
+	addr, err := mail.ParseAddress(v)
+	return err == nil && addr.Address == v
+}
+
+func isFile(v string) bool {
+	fileInfo, err := os.Stat(v)
+	return err == nil && !fileInfo.IsDir()
+}
+
+func isDir(v string) bool {
+	fileInfo, err := os.Stat(v)
+	return err == nil && fileInfo.IsDir()
+}
+
+// isWritableDir reports whether a file can be created in the directory v.
+func isWritableDir(v string) bool {
+	if !isDir(v) {
+		return false
+	}
+	f, err := os.CreateTemp(v, ".envar-writable-*")
+	if err != nil {
+		return false
+	}
+	f.Close()
+	os.Remove(f.Name())
+	return true
+}
+
+var uuidRegexp = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// isBase64 reports whether v is standard base64, with or without padding.
+func isBase64(v string) bool {
+	if _, err := base64.StdEncoding.DecodeString(v); err == nil {
+		return true
+	}
+	_, err := base64.RawStdEncoding.DecodeString(v)
+	return err == nil
+}
+
+func isHex(v string) bool {
+	_, err := hex.DecodeString(v)
+	return err == nil
+}
+
+func isLowercase(v string) bool {
+	return v == strings.ToLower(v)
+}
+
+func isPrintable(v string) bool {
+	for _, r := range v {
+		if !unicode.IsPrint(r) {
+			return false
+		}
+	}
+	return true
+}
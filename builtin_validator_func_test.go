@@ -0,0 +1,129 @@
+package envar
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type builtinValidateConfig struct {
+	URL         string   `env:"URL,validate=url"`
+	HTTPURL     string   `env:"HTTP_URL,validate=http_url"`
+	HostPort    string   `env:"HOST_PORT,validate=hostport"`
+	Port        int      `env:"PORT,validate=port"`
+	IP          string   `env:"IP,validate=ip"`
+	IPv4        string   `env:"IPV4,validate=ipv4"`
+	IPv6        string   `env:"IPV6,validate=ipv6"`
+	CIDR        string   `env:"CIDR,validate=cidr"`
+	Email       string   `env:"EMAIL,validate=email"`
+	File        string   `env:"FILE,validate=file_exists"`
+	Dir         string   `env:"DIR,validate=dir_exists"`
+	WritableDir string   `env:"WRITABLE_DIR,validate=writable_dir"`
+	AbsPath     string   `env:"ABS_PATH,validate=abs_path"`
+	UUID        string   `env:"UUID,validate=uuid"`
+	Base64      string   `env:"BASE64,validate=base64"`
+	Hex         string   `env:"HEX,validate=hex"`
+	Lowercase   string   `env:"LOWERCASE,validate=lowercase"`
+	Printable   string   `env:"PRINTABLE,validate=printable"`
+	Peers       []string `env:"PEERS,validate=hostport"`
+	Ports       []int    `env:"PORTS,validate=port"`
+}
+
+func TestParse_BuiltinValidators(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "file")
+	require.NoError(t, os.WriteFile(file, []byte("content"), 0o600))
+	absPath := filepath.Join(dir, "missing")
+
+	cfg := builtinValidateConfig{}
+	_, err := Parse(&cfg, SetEnvVarsLoaderFunc(EnvVarsMapLoaderFunc(EnvVarsMap{
+		"URL":          "postgres://user@localhost:5432/db",
+		"HTTP_URL":     "https://example.com/path",
+		"HOST_PORT":    ":8080",
+		"PORT":         "65535",
+		"IP":           "::1",
+		"IPV4":         "10.0.0.1",
+		"IPV6":         "2001:db8::1",
+		"CIDR":         "10.0.0.0/8",
+		"EMAIL":        "ops@example.com",
+		"FILE":         file,
+		"DIR":          dir,
+		"WRITABLE_DIR": dir,
+		"ABS_PATH":     absPath,
+		"UUID":         "123e4567-e89b-12d3-a456-426614174000",
+		"BASE64":       "aGVsbG8=",
+		"HEX":          "deadBEEF",
+		"LOWERCASE":    "lower-case_1",
+		"PRINTABLE":    "tab-free text",
+		"PEERS":        "a:1,b:2",
+		"PORTS":        "80,443",
+	})))
+	require.NoError(t, err)
+	require.Equal(t, []int{80, 443}, cfg.Ports)
+
+	_, err = Parse(&builtinValidateConfig{}, SetEnvVarsLoaderFunc(EnvVarsMapLoaderFunc(EnvVarsMap{
+		"URL":          "not a url",
+		"HTTP_URL":     "ftp://example.com",
+		"HOST_PORT":    "localhost:http",
+		"PORT":         "0",
+		"IP":           "256.0.0.1",
+		"IPV4":         "::ffff:10.0.0.1",
+		"IPV6":         "10.0.0.1",
+		"CIDR":         "10.0.0.0",
+		"EMAIL":        "Ops <ops@example.com>",
+		"FILE":         dir,
+		"DIR":          file,
+		"WRITABLE_DIR": filepath.Join(dir, "missing"),
+		"ABS_PATH":     "relative/path",
+		"UUID":         "123e4567e89b12d3a456426614174000",
+		"BASE64":       "not base64!",
+		"HEX":          "abc",
+		"LOWERCASE":    "Mixed",
+		"PRINTABLE":    "bell\a",
+		"PEERS":        "a:1,b",
+		"PORTS":        "80,70000",
+	})))
+	validationErrs := &ValidationErrors{}
+	require.ErrorAs(t, err, &validationErrs)
+
+	list := validationErrs.Errors.List()
+	rules := make([]string, len(list))
+	for i := range list {
+		rules[i] = list[i].Rule
+	}
+	sort.Strings(rules)
+	require.Equal(t, []string{
+		"abs_path", "base64", "cidr", "dir_exists", "email", "file_exists",
+		"hex", "hostport", "hostport", "http_url", "ip", "ipv4", "ipv6",
+		"lowercase", "port", "port", "printable", "url", "uuid", "writable_dir",
+	}, rules)
+
+	require.Equal(t, []ValidationError{{
+		FieldPath: "builtinValidateConfig.Peers",
+		EnvKey:    "PEERS",
+		Rule:      "hostport",
+		Message:   "env key: PEERS value b is not a valid host:port",
+	}}, validationErrs.Errors.Get("builtinValidateConfig.Peers"))
+	require.Equal(t,
+		"env key: PORTS value 70000 is not a valid port",
+		validationErrs.Errors.Get("builtinValidateConfig.Ports")[0].Message,
+	)
+}
+
+func TestParse_BuiltinValidatorsSensitiveSlice(t *testing.T) {
+	type config struct {
+		Hosts []string `env:"HOSTS,sensitive,validate=ip"`
+	}
+
+	ctx, err := Parse(&config{}, SetEnvVarsLoaderFunc(EnvVarsMapLoaderFunc(EnvVarsMap{"HOSTS": "1.2.3.4,secretvalue"})))
+	require.Error(t, err)
+	require.NotContains(t, err.Error(), "secretvalue")
+	require.Equal(
+		t,
+		"env key: HOSTS value ****** is not a valid IP address",
+		ctx.GetValidationErrors().Get("config.Hosts")[0].Message,
+	)
+}
@@ -0,0 +1,151 @@
+// Command envardoc writes a table documenting the env vars of a config struct.
+//
+// It must be run from within the module that contains the struct, e.g.
+//
+//	envardoc -pkg github.com/acme/app/config -type Config -format markdown
+//
+// A temporary program that imports the package and calls envar.WriteDocs is
+// generated and run with `go run`, so that the struct tags are read by the
+// same code that parses them.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/token"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+var program = template.Must(template.New("main").Parse(`package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/neumachen/envar"
+	pkg {{ printf "%q" .Package }}
+)
+
+func main() {
+	err := envar.WriteDocs(
+		os.Stdout,
+		envar.DocFormat({{ printf "%q" .Format }}),
+		&pkg.{{ .Type }}{},
+		envar.SetTagName({{ printf "%q" .TagName }}),
+		envar.SetEnvPrefix({{ printf "%q" .Prefix }}),
+	)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+`))
+
+type params struct {
+	Package string
+	Type    string
+	Format  string
+	TagName string
+	Prefix  string
+}
+
+func main() {
+	p := params{}
+	output := ""
+	flag.StringVar(&p.Package, "pkg", "", "import path of the package that contains the struct")
+	flag.StringVar(&p.Type, "type", "", "name of the struct, optionally qualified by the package name")
+	flag.StringVar(&p.Format, "format", "markdown", "output format: markdown or text")
+	flag.StringVar(&p.TagName, "tag", "env", "tag name used by the struct fields")
+	flag.StringVar(&p.Prefix, "prefix", "", "env prefix")
+	flag.StringVar(&output, "o", "", "output file, defaults to stdout")
+	flag.Parse()
+
+	if p.Package == "" || p.Type == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	typeName, err := parseTypeName(p.Type)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "envardoc:", err)
+		os.Exit(2)
+	}
+	p.Type = typeName
+
+	if err := run(p, output); err != nil {
+		fmt.Fprintln(os.Stderr, "envardoc:", err)
+		os.Exit(1)
+	}
+}
+
+// parseTypeName returns the name of the struct given to -type, which is
+// inserted in the generated program and so must be an identifier. A package
+// qualifier, e.g. config.Config, is dropped as the package is imported as pkg.
+func parseTypeName(v string) (string, error) {
+	name := v
+	if qualifier, rest, ok := strings.Cut(v, "."); ok {
+		if !token.IsIdentifier(qualifier) {
+			return "", fmt.Errorf("-type %q is not a type name", v)
+		}
+		name = rest
+	}
+	if !token.IsIdentifier(name) {
+		return "", fmt.Errorf("-type %q is not a type name", v)
+	}
+	return name, nil
+}
+
+func run(p params, output string) error {
+	// the program has to live within the module so that the package can be
+	// imported
+	dir, err := os.MkdirTemp(".", ".envardoc")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	src := bytes.Buffer{}
+	if err := program.Execute(&src, p); err != nil {
+		return err
+	}
+	mainFile := filepath.Join(dir, "main.go")
+	if err := os.WriteFile(mainFile, src.Bytes(), 0o600); err != nil {
+		return err
+	}
+
+	cmd := exec.Command("go", "run", mainFile)
+	cmd.Stderr = os.Stderr
+	if output == "" {
+		cmd.Stdout = os.Stdout
+		return cmd.Run()
+	}
+
+	// the output is written to a temporary file that replaces the output
+	// file only once the program succeeded, so that a failed run does not
+	// leave it empty
+	f, err := os.CreateTemp(filepath.Dir(output), ".envardoc-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	// os.CreateTemp creates the file readable by the owner only
+	if err := f.Chmod(0o644); err != nil {
+		f.Close()
+		return err
+	}
+
+	cmd.Stdout = f
+	err = cmd.Run()
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), output)
+}
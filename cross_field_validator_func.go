@@ -0,0 +1,246 @@
+package envar
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/neumachen/errorx"
+	"github.com/neumachen/gobag"
+)
+
+// CrossFieldValidatorFunc validates a field against other fields or env vars,
+// e.g. a field that is required when another one is set. It runs once every
+// field of the struct was parsed and receives the fields of the struct, the
+// EnvVarsMap is available through the parserCtx. The param is the one of the
+// rule, e.g. MODE=cluster for required_if=MODE=cluster.
+type CrossFieldValidatorFunc func(
+	parserCtx ParserCtxAccessor,
+	parsedField ParsedFieldGetter,
+	siblings ParsedFields,
+	param string,
+) error
+
+type CrossFieldValidatorFuncsMap map[string]CrossFieldValidatorFunc
+
+func (v CrossFieldValidatorFuncsMap) GetLength() int {
+	return len(v)
+}
+
+func (v CrossFieldValidatorFuncsMap) Add(key string, fn CrossFieldValidatorFunc) {
+	v[key] = fn
+}
+
+var defaultCrossFieldValidatorsFunc = CrossFieldValidatorFuncsMap{
+	"required_if":      validateRequiredIf,
+	"required_with":    validateRequiredWith,
+	"required_without": validateRequiredWithout,
+	"excluded_with":    validateExcludedWith,
+	"exactly_one_of":   validateExactlyOneOf,
+	"at_least_one_of":  validateAtLeastOneOf,
+}
+
+// ParsedFields are the fields of a struct, nested structs excluded.
+type ParsedFields []ParsedFieldGetter
+
+// Lookup returns the field whose name, e.g. TLSCert, or env name without the
+// prefix, e.g. TLS_CERT, is the ref.
+func (p ParsedFields) Lookup(ref string) (ParsedFieldGetter, bool) {
+	for i := range p {
+		if p[i].GetStructField().Name == ref {
+			return p[i], true
+		}
+	}
+	for i := range p {
+		if p[i].GetEnvName() == ref {
+			return p[i], true
+		}
+	}
+	return nil, false
+}
+
+// lookupRef returns the env key and the value of the ref, which is either a
+// field of the struct, see ParsedFields.Lookup, or an env key. The value of a
+// field includes its default unless explicit is true.
+func lookupRef(parserCtx ParserCtxGetter, siblings ParsedFields, ref string, explicit bool) (key, value string) {
+	if parsedField, ok := siblings.Lookup(ref); ok {
+		if explicit {
+			return parsedField.GetEnvKey(), parsedField.GetEnvValue()
+		}
+		return parsedField.GetEnvKey(), parsedField.GetFieldValue()
+	}
+	value, _ = parserCtx.GetEnvVarsMap().Get(ref)
+	return ref, value
+}
+
+// isFieldSet reports whether the field has a value, its default included
+// unless explicit is true.
+func isFieldSet(parsedField ParsedFieldGetter, explicit bool) bool {
+	if explicit {
+		return !gobag.StringIsEmpty(parsedField.GetEnvValue())
+	}
+	return !gobag.StringIsEmpty(parsedField.GetFieldValue())
+}
+
+// validateRequiredIf requires the field when one of the space separated
+// REF=value conditions of the param is met.
+func validateRequiredIf(
+	parserCtx ParserCtxAccessor,
+	parsedField ParsedFieldGetter,
+	siblings ParsedFields,
+	param string,
+) error {
+	for _, cond := range strings.Fields(param) {
+		ref, want, ok := strings.Cut(cond, "=")
+		if !ok {
+			return errorx.New(fmt.Sprintf(
+				"validator func: %s: invalid param %q: expected REF=value", parsedField.GetValidateRule(), cond,
+			))
+		}
+		key, v := lookupRef(parserCtx, siblings, ref, false)
+		if v == want && !isFieldSet(parsedField, false) {
+			parserCtx.AddFieldValidationError(NewValidationError(
+				parsedField,
+				fmt.Sprintf("env key: %s is required when %s is %s", parsedField.GetEnvKey(), key, want),
+			))
+			return nil
+		}
+	}
+	return nil
+}
+
+// validateRequiredWith requires the field when one of the refs is set.
+func validateRequiredWith(
+	parserCtx ParserCtxAccessor,
+	parsedField ParsedFieldGetter,
+	siblings ParsedFields,
+	param string,
+) error {
+	if isFieldSet(parsedField, false) {
+		return nil
+	}
+	for _, ref := range strings.Fields(param) {
+		if key, v := lookupRef(parserCtx, siblings, ref, false); !gobag.StringIsEmpty(v) {
+			parserCtx.AddFieldValidationError(NewValidationError(
+				parsedField,
+				fmt.Sprintf("env key: %s is required when %s is set", parsedField.GetEnvKey(), key),
+			))
+			return nil
+		}
+	}
+	return nil
+}
+
+// validateRequiredWithout requires the field when one of the refs is not set.
+func validateRequiredWithout(
+	parserCtx ParserCtxAccessor,
+	parsedField ParsedFieldGetter,
+	siblings ParsedFields,
+	param string,
+) error {
+	if isFieldSet(parsedField, false) {
+		return nil
+	}
+	for _, ref := range strings.Fields(param) {
+		if key, v := lookupRef(parserCtx, siblings, ref, false); gobag.StringIsEmpty(v) {
+			parserCtx.AddFieldValidationError(NewValidationError(
+				parsedField,
+				fmt.Sprintf("env key: %s is required when %s is not set", parsedField.GetEnvKey(), key),
+			))
+			return nil
+		}
+	}
+	return nil
+}
+
+// validateExcludedWith forbids the field when one of the refs is set. Only
+// the env vars that are set count, defaults are ignored.
+func validateExcludedWith(
+	parserCtx ParserCtxAccessor,
+	parsedField ParsedFieldGetter,
+	siblings ParsedFields,
+	param string,
+) error {
+	if !isFieldSet(parsedField, true) {
+		return nil
+	}
+	for _, ref := range strings.Fields(param) {
+		if key, v := lookupRef(parserCtx, siblings, ref, true); !gobag.StringIsEmpty(v) {
+			parserCtx.AddFieldValidationError(NewValidationError(
+				parsedField,
+				fmt.Sprintf("env key: %s must not be set when %s is set", parsedField.GetEnvKey(), key),
+			))
+			return nil
+		}
+	}
+	return nil
+}
+
+// countSet returns the env keys of the field and of the refs, and how many of
+// them are set, see lookupRef.
+func countSet(
+	parserCtx ParserCtxGetter,
+	parsedField ParsedFieldGetter,
+	siblings ParsedFields,
+	param string,
+	explicit bool,
+) ([]string, int) {
+	keys := []string{parsedField.GetEnvKey()}
+	n := 0
+	if isFieldSet(parsedField, explicit) {
+		n++
+	}
+	for _, ref := range strings.Fields(param) {
+		key, v := lookupRef(parserCtx, siblings, ref, explicit)
+		keys = append(keys, key)
+		if !gobag.StringIsEmpty(v) {
+			n++
+		}
+	}
+	return keys, n
+}
+
+// validateExactlyOneOf requires exactly one of the field and the refs to be
+// set. Only the env vars that are set count, defaults are ignored.
+func validateExactlyOneOf(
+	parserCtx ParserCtxAccessor,
+	parsedField ParsedFieldGetter,
+	siblings ParsedFields,
+	param string,
+) error {
+	if keys, n := countSet(parserCtx, parsedField, siblings, param, true); n != 1 {
+		parserCtx.AddFieldValidationError(NewValidationError(
+			parsedField,
+			fmt.Sprintf("exactly one of %s must be set, %d are set", strings.Join(keys, ", "), n),
+		))
+	}
+	return nil
+}
+
+// validateAtLeastOneOf requires at least one of the field and the refs to be
+// set.
+func validateAtLeastOneOf(
+	parserCtx ParserCtxAccessor,
+	parsedField ParsedFieldGetter,
+	siblings ParsedFields,
+	param string,
+) error {
+	if keys, n := countSet(parserCtx, parsedField, siblings, param, false); n < 1 {
+		parserCtx.AddFieldValidationError(NewValidationError(
+			parsedField,
+			fmt.Sprintf("at least one of %s must be set", strings.Join(keys, ", ")),
+		))
+	}
+	return nil
+}
+
+// validateCrossField runs the CrossFieldValidatorFuncs of the field once the
+// siblings were parsed.
+func (p *parsedField) validateCrossField(parserCtx *ParserCtx, siblings ParsedFields) error {
+	return p.runValidators(parserCtx, func(name, param string, _ bool) (bool, error) {
+		if cFunc, ok := parserCtx.crossFieldValidatorFuncsMap[name]; ok {
+			return true, cFunc(parserCtx, p, siblings, param)
+		}
+		// the other rules were run by validate and validateTyped
+		return true, nil
+	})
+}
@@ -0,0 +1,145 @@
+package envar
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type crossFieldTLS struct {
+	Cert string `env:"CERT"`
+	Key  string `env:"KEY,validate=required_with=CERT"`
+}
+
+type crossFieldConfig struct {
+	Mode       string        `env:"MODE,default=standalone"`
+	Peers      []string      `env:"PEERS,validate=required_if=MODE=cluster"`
+	Host       string        `env:"HOST,validate=exactly_one_of=SocketPath"`
+	SocketPath string        `env:"SOCKET_PATH"`
+	Port       int           `env:"PORT,default=8080,validate=excluded_with=SOCKET_PATH"`
+	Token      string        `env:"TOKEN,validate=required_without=TOKEN_FILE"`
+	Email      string        `env:"EMAIL,validate=at_least_one_of=Phone"`
+	Phone      string        `env:"PHONE"`
+	CertFile   string        `env:"CERT_FILE"`
+	KeyFile    string        `env:"KEY_FILE,validate=required_with=CertFile"`
+	TLS        crossFieldTLS `env:"TLS,nested"`
+}
+
+func TestParse_CrossFieldValidators(t *testing.T) {
+	cfg := crossFieldConfig{}
+	_, err := Parse(&cfg, SetEnvVarsLoaderFunc(EnvVarsMapLoaderFunc(EnvVarsMap{
+		"MODE":       "cluster",
+		"PEERS":      "a,b",
+		"HOST":       "localhost",
+		"TOKEN_FILE": "/run/secrets/token",
+		"PHONE":      "555-0100",
+		"EMAIL":      "ops@example.com",
+	})))
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b"}, cfg.Peers)
+
+	// the default of PORT doesn't conflict with SOCKET_PATH
+	_, err = Parse(&crossFieldConfig{}, SetEnvVarsLoaderFunc(EnvVarsMapLoaderFunc(EnvVarsMap{
+		"SOCKET_PATH": "/run/app.sock",
+		"TOKEN":       "token",
+		"EMAIL":       "ops@example.com",
+	})))
+	require.NoError(t, err)
+
+	_, err = Parse(&crossFieldConfig{}, SetEnvVarsLoaderFunc(EnvVarsMapLoaderFunc(EnvVarsMap{
+		"MODE":        "cluster",
+		"HOST":        "localhost",
+		"SOCKET_PATH": "/run/app.sock",
+		"PORT":        "9090",
+		"CERT_FILE":   "cert.pem",
+		"TLS_CERT":    "cert.pem",
+	})))
+	validationErrs := &ValidationErrors{}
+	require.ErrorAs(t, err, &validationErrs)
+	require.Equal(t, []ValidationError{
+		{
+			FieldPath: "crossFieldConfig.Email",
+			EnvKey:    "EMAIL",
+			Rule:      "at_least_one_of",
+			Args:      []string{"Phone"},
+			Message:   "at least one of EMAIL, PHONE must be set",
+		},
+		{
+			FieldPath: "crossFieldConfig.Host",
+			EnvKey:    "HOST",
+			Rule:      "exactly_one_of",
+			Args:      []string{"SocketPath"},
+			Message:   "exactly one of HOST, SOCKET_PATH must be set, 2 are set",
+		},
+		{
+			FieldPath: "crossFieldConfig.KeyFile",
+			EnvKey:    "KEY_FILE",
+			Rule:      "required_with",
+			Args:      []string{"CertFile"},
+			Message:   "env key: KEY_FILE is required when CERT_FILE is set",
+		},
+		{
+			FieldPath: "crossFieldConfig.Peers",
+			EnvKey:    "PEERS",
+			Rule:      "required_if",
+			Args:      []string{"MODE=cluster"},
+			Message:   "env key: PEERS is required when MODE is cluster",
+		},
+		{
+			FieldPath: "crossFieldConfig.Port",
+			EnvKey:    "PORT",
+			Rule:      "excluded_with",
+			Args:      []string{"SOCKET_PATH"},
+			Message:   "env key: PORT must not be set when SOCKET_PATH is set",
+		},
+		{
+			FieldPath: "crossFieldConfig.TLS.Key",
+			EnvKey:    "TLS_KEY",
+			Rule:      "required_with",
+			Args:      []string{"CERT"},
+			Message:   "env key: TLS_KEY is required when TLS_CERT is set",
+		},
+		{
+			FieldPath: "crossFieldConfig.Token",
+			EnvKey:    "TOKEN",
+			Rule:      "required_without",
+			Args:      []string{"TOKEN_FILE"},
+			Message:   "env key: TOKEN is required when TOKEN_FILE is not set",
+		},
+	}, validationErrs.Errors.List())
+}
+
+func TestParse_AddCrossFieldValidatorFunc(t *testing.T) {
+	type config struct {
+		Min int `env:"MIN"`
+		Max int `env:"MAX,validate=gte_field=Min"`
+	}
+
+	gteField := func(parserCtx ParserCtxAccessor, parsedField ParsedFieldGetter, siblings ParsedFields, param string) error {
+		other, ok := siblings.Lookup(param)
+		require.True(t, ok)
+		require.Equal(t, "2", parserCtx.GetEnvVarsMap()["MIN"])
+		if parsedField.GetFieldValue() < other.GetFieldValue() {
+			parserCtx.AddFieldValidationError(NewValidationError(parsedField, "MAX is less than MIN"))
+		}
+		return nil
+	}
+
+	_, err := Parse(
+		&config{},
+		AddCrossFieldValidatorFunc("gte_field", gteField),
+		SetEnvVarsLoaderFunc(EnvVarsMapLoaderFunc(EnvVarsMap{"MIN": "2", "MAX": "1"})),
+	)
+	validationErrs := &ValidationErrors{}
+	require.ErrorAs(t, err, &validationErrs)
+	require.Equal(t, "MAX is less than MIN", validationErrs.Errors.Get("config.Max")[0].Message)
+}
+
+func TestParse_RequiredIf_InvalidParam(t *testing.T) {
+	type config struct {
+		Peers string `env:"PEERS,validate=required_if=MODE"`
+	}
+	_, err := Parse(&config{}, SetEnvVarsLoaderFunc(EnvVarsMapLoaderFunc(EnvVarsMap{"MODE": "x"})))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `validator func: required_if: invalid param "MODE": expected REF=value`)
+}
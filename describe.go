@@ -0,0 +1,247 @@
+package envar
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/neumachen/errorx"
+	"github.com/neumachen/gobag"
+)
+
+// DocFormat is the output format of WriteDocs.
+type DocFormat string
+
+const (
+	// DocFormatMarkdown writes a Markdown table.
+	DocFormatMarkdown DocFormat = "markdown"
+	// DocFormatText writes a plain text table.
+	DocFormatText DocFormat = "text"
+)
+
+// NestedSliceIndexPlaceholder is used in place of the index in the env keys
+// and field paths of a described slice of nested structs, e.g. SERVERS_N_HOST
+const NestedSliceIndexPlaceholder = "N"
+
+// FieldDoc describes a struct field that is loaded from an env var.
+type FieldDoc struct {
+	// FieldPath is the path of the field, e.g. Config.DB.Host
+	FieldPath string
+	// EnvKey is the env key, prefix included, that is looked up for the
+	// field.
+	EnvKey string
+	// Type is the Go type of the field.
+	Type string
+	// Default is the value of the default tag option.
+	Default string
+	// Validators are the rules of the validate tag option.
+	Validators []string
+	// Description is the value of the desc tag option.
+	Description string
+	// Required reports whether the required validator is used.
+	Required bool
+	// AllowedValues are the values the field accepts, when they are known.
+	AllowedValues []string
+	// Sensitive reports whether the field has the sensitive tag option or is
+	// a Secret.
+	Sensitive bool
+}
+
+// Describe walks the struct, or pointer to struct, v the same way Parse does
+// and describes each field that is loaded from an env var. Nested structs are
+// described as well, the index of a slice of nested structs is replaced by
+// NestedSliceIndexPlaceholder. The env vars are not loaded.
+func Describe(v interface{}, setterFuncs ...ParserCtxFuncSetter) ([]FieldDoc, error) {
+	return describeStruct(v, NestedSliceIndexPlaceholder, setterFuncs...)
+}
+
+// describeStruct is Describe using idx in place of the index of a slice of
+// nested structs.
+func describeStruct(v interface{}, idx string, setterFuncs ...ParserCtxFuncSetter) ([]FieldDoc, error) {
+	rType := reflect.TypeOf(v)
+	if rType != nil && rType.Kind() == reflect.Ptr {
+		rType = rType.Elem()
+	}
+	if rType == nil || rType.Kind() != reflect.Struct {
+		return nil, ErrNotAStructPtr
+	}
+
+	parserCtx, err := newParserCtx(setterFuncs...)
+	if err != nil {
+		return nil, err
+	}
+
+	scope := parseScope{
+		fieldPath: rType.Name(),
+		envPrefix: parserCtx.GetEnvPrefix(),
+	}
+	docs := make([]FieldDoc, 0, rType.NumField())
+	if err := describe(parserCtx, rType, scope, idx, &docs); err != nil {
+		return nil, err
+	}
+	return docs, nil
+}
+
+func describe(parserCtx *ParserCtx, rType reflect.Type, scope parseScope, idx string, docs *[]FieldDoc) error {
+	for i := 0; i < rType.NumField(); i++ {
+		structField := rType.Field(i)
+		if !structField.IsExported() {
+			continue
+		}
+
+		parsedField, err := newParsedField(parserCtx, structField, scope)
+		if err != nil {
+			return newFieldError(scope.joinFieldPath(structField.Name), "", err)
+		}
+		if parsedField == nil {
+			continue
+		}
+
+		if parsedField.isNested() {
+			nestedType := structField.Type
+			nestedScope := parsedField.nestedScope()
+			if nestedType.Kind() == reflect.Slice {
+				nestedType = nestedType.Elem()
+				if parsedField.hasNestedEnvName() {
+					nestedScope = parsedField.nestedElemScope(idx)
+				}
+			}
+			if nestedType.Kind() == reflect.Ptr {
+				nestedType = nestedType.Elem()
+			}
+			if nestedType.Kind() != reflect.Struct {
+				return newFieldError(
+					parsedField.GetFieldPath(),
+					"",
+					errorx.New(fmt.Sprintf("field: %v is not a struct but has nested tag option", structField.Type)),
+				)
+			}
+			if err := describe(parserCtx, nestedType, nestedScope, idx, docs); err != nil {
+				return err
+			}
+			continue
+		}
+
+		// fields without an env name, e.g. a struct that is not nested, are
+		// never loaded
+		if parsedField.GetEnvName() == "" {
+			continue
+		}
+
+		validators := parsedField.tagOpts.getValidate()
+		*docs = append(*docs, FieldDoc{
+			FieldPath:     parsedField.GetFieldPath(),
+			EnvKey:        parsedField.GetEnvKey(),
+			Type:          structField.Type.String(),
+			Default:       parsedField.GetDefaultValue(),
+			Validators:    validators,
+			Description:   parsedField.GetDescription(),
+			Required:      gobag.ArrayContainsStr(validators, "required"),
+			AllowedValues: allowedValues(structField.Type, validators),
+			Sensitive:     parsedField.isSensitive(),
+		})
+	}
+	return nil
+}
+
+// allowedValues returns the values that are accepted for the rType, if they
+// are known, either from its kind or from the oneof validator.
+func allowedValues(rType reflect.Type, validators []string) []string {
+	for i := range validators {
+		if name, param, ok := splitValidateRule(validators[i]); ok && name == "oneof" {
+			return strings.Fields(param)
+		}
+	}
+	if rType.Kind() == reflect.Ptr {
+		rType = rType.Elem()
+	}
+	if rType.Kind() == reflect.Bool {
+		return []string{"true", "false"}
+	}
+	return nil
+}
+
+// WriteDocs writes a table describing the env vars of the struct, or pointer
+// to struct, v to w. See Describe.
+func WriteDocs(w io.Writer, format DocFormat, v interface{}, setterFuncs ...ParserCtxFuncSetter) error {
+	docs, err := Describe(v, setterFuncs...)
+	if err != nil {
+		return err
+	}
+
+	header := []string{"Env key", "Type", "Default", "Validators", "Field", "Description"}
+	rows := make([][]string, len(docs))
+	for i := range docs {
+		rows[i] = []string{
+			docs[i].EnvKey,
+			docs[i].Type,
+			docs[i].Default,
+			strings.Join(docs[i].Validators, ", "),
+			docs[i].FieldPath,
+			docs[i].Description,
+		}
+	}
+
+	switch format {
+	case DocFormatMarkdown:
+		return writeMarkdownTable(w, header, rows)
+	case DocFormatText:
+		return writeTextTable(w, header, rows)
+	}
+	return errorx.New(fmt.Sprintf("env: unknown doc format: %s", format))
+}
+
+func writeMarkdownTable(w io.Writer, header []string, rows [][]string) error {
+	escape := strings.NewReplacer("|", `\|`, "\n", " ")
+	writeRow := func(cells []string, code map[int]bool) error {
+		b := strings.Builder{}
+		b.WriteString("|")
+		for i := range cells {
+			cell := escape.Replace(cells[i])
+			if code[i] && cell != "" {
+				cell = "`" + cell + "`"
+			}
+			b.WriteString(" " + cell + " |")
+		}
+		b.WriteString("\n")
+		_, err := io.WriteString(w, b.String())
+		return err
+	}
+
+	if err := writeRow(header, nil); err != nil {
+		return errorx.New(err)
+	}
+	separator := make([]string, len(header))
+	for i := range separator {
+		separator[i] = "---"
+	}
+	if err := writeRow(separator, nil); err != nil {
+		return errorx.New(err)
+	}
+	// env key, type and default are rendered as code
+	code := map[int]bool{0: true, 1: true, 2: true}
+	for i := range rows {
+		if err := writeRow(rows[i], code); err != nil {
+			return errorx.New(err)
+		}
+	}
+	return nil
+}
+
+func writeTextTable(w io.Writer, header []string, rows [][]string) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if _, err := fmt.Fprintln(tw, strings.Join(header, "\t")); err != nil {
+		return errorx.New(err)
+	}
+	for i := range rows {
+		if _, err := fmt.Fprintln(tw, strings.Join(rows[i], "\t")); err != nil {
+			return errorx.New(err)
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		return errorx.New(err)
+	}
+	return nil
+}
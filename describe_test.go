@@ -0,0 +1,111 @@
+package envar
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type describeDB struct {
+	Host string `env:"HOST,validate=required|not_empty,desc=database host"`
+	Port int    `env:"PORT,default=5432"`
+}
+
+type describeConfig struct {
+	DB       describeDB    `env:"DB,nested"`
+	Replicas []describeDB  `env:"REPLICAS,nested"`
+	Timeout  time.Duration `env:"TIMEOUT,default=5s,desc=request timeout"`
+	NotAnEnv string
+	internal string `env:"INTERNAL"`
+}
+
+func TestDescribe(t *testing.T) {
+	docs, err := Describe(&describeConfig{}, SetEnvPrefix("APP"))
+	require.NoError(t, err)
+	require.Equal(t, []FieldDoc{
+		{
+			FieldPath:   "describeConfig.DB.Host",
+			EnvKey:      "APP_DB_HOST",
+			Type:        "string",
+			Validators:  []string{"required", "not_empty"},
+			Description: "database host",
+			Required:    true,
+		},
+		{
+			FieldPath: "describeConfig.DB.Port",
+			EnvKey:    "APP_DB_PORT",
+			Type:      "int",
+			Default:   "5432",
+		},
+		{
+			FieldPath:   "describeConfig.Replicas[N].Host",
+			EnvKey:      "APP_REPLICAS_N_HOST",
+			Type:        "string",
+			Validators:  []string{"required", "not_empty"},
+			Description: "database host",
+			Required:    true,
+		},
+		{
+			FieldPath: "describeConfig.Replicas[N].Port",
+			EnvKey:    "APP_REPLICAS_N_PORT",
+			Type:      "int",
+			Default:   "5432",
+		},
+		{
+			FieldPath:   "describeConfig.Timeout",
+			EnvKey:      "APP_TIMEOUT",
+			Type:        "time.Duration",
+			Default:     "5s",
+			Description: "request timeout",
+		},
+	}, docs)
+
+	_, err = Describe("not a struct")
+	require.ErrorIs(t, err, ErrNotAStructPtr)
+}
+
+func TestWriteDocs(t *testing.T) {
+	type config struct {
+		Level string `env:"LEVEL,default=info,validate=not_empty,desc=log level"`
+	}
+
+	b := bytes.Buffer{}
+	require.NoError(t, WriteDocs(&b, DocFormatMarkdown, config{}))
+	require.Equal(t, "| Env key | Type | Default | Validators | Field | Description |\n"+
+		"| --- | --- | --- | --- | --- | --- |\n"+
+		"| `LEVEL` | `string` | `info` | not_empty | config.Level | log level |\n", b.String())
+
+	b.Reset()
+	require.NoError(t, WriteDocs(&b, DocFormatText, config{}))
+	require.Equal(t, "Env key  Type    Default  Validators  Field         Description\n"+
+		"LEVEL    string  info     not_empty   config.Level  log level\n", b.String())
+
+	require.Error(t, WriteDocs(&b, DocFormat("html"), config{}))
+}
+
+func TestDescribe_OneOf(t *testing.T) {
+	docs, err := Describe(paramValidateConfig{})
+	require.NoError(t, err)
+	require.Equal(t, "LOG_LEVEL", docs[4].EnvKey)
+	require.Equal(t, []string{"debug", "info", "warn"}, docs[4].AllowedValues)
+	require.Equal(t, []string{"oneof=debug info warn"}, docs[4].Validators)
+}
+
+func TestDescribe_EscapedComma(t *testing.T) {
+	type config struct {
+		Host string `env:"HOST,desc=database host\\, without port,default=localhost"`
+	}
+
+	docs, err := Describe(config{})
+	require.NoError(t, err)
+	require.Len(t, docs, 1)
+	require.Equal(t, "database host, without port", docs[0].Description)
+	require.Equal(t, "localhost", docs[0].Default)
+
+	cfg := config{}
+	_, err = Parse(&cfg, SetEnvVarsLoaderFunc(EnvVarsMapLoaderFunc(EnvVarsMap{})))
+	require.NoError(t, err)
+	require.Equal(t, "localhost", cfg.Host)
+}
@@ -0,0 +1,296 @@
+package envar
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/neumachen/errorx"
+)
+
+func newDotEnvError(line int, msg string) error {
+	return dotEnvError{
+		line: line,
+		msg:  msg,
+	}
+}
+
+type dotEnvError struct {
+	path string
+	line int
+	msg  string
+}
+
+func (e dotEnvError) Error() string {
+	if e.path == "" {
+		return fmt.Sprintf("env: dotenv line %d: %s", e.line, e.msg)
+	}
+	return fmt.Sprintf("env: dotenv file %s line %d: %s", e.path, e.line, e.msg)
+}
+
+// ParseDotEnv parses the dotenv formatted content of r into an EnvVarsMap.
+//
+// Each entry is a KEY=VALUE pair optionally prefixed with `export`. Values
+// can be unquoted, single quoted (taken literally) or double quoted (\n, \r,
+// \t, \\ and \" escapes are interpreted). Quoted values can span multiple
+// lines. Blank lines and lines starting with # are ignored, as is anything
+// following a # that is preceded by whitespace outside of quotes. When a key
+// is defined more than once the last definition wins.
+func ParseDotEnv(r io.Reader) (EnvVarsMap, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, errorx.New(err)
+	}
+
+	p := &dotEnvParser{
+		src:  strings.ReplaceAll(string(content), "\r\n", "\n"),
+		line: 1,
+	}
+	eMap := make(EnvVarsMap)
+	for {
+		key, value, ok, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return eMap, nil
+		}
+		eMap.Set(key, value)
+	}
+}
+
+// ReadDotEnvFile reads the dotenv file found at path, see ParseDotEnv for the
+// supported format.
+func ReadDotEnvFile(path string) (EnvVarsMap, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errorx.New(err)
+	}
+	defer f.Close()
+
+	eMap, err := ParseDotEnv(f)
+	if err != nil {
+		if dErr, ok := err.(dotEnvError); ok {
+			dErr.path = path
+			return nil, dErr
+		}
+		return nil, err
+	}
+	return eMap, nil
+}
+
+// SetDotEnvFile reads the dotenv file found at path and uses its entries as
+// the EnvVarsMap instead of the environment variables of the process.
+func SetDotEnvFile(path string) ParserCtxFuncSetter {
+	return func(setter ParserCtxSetter) error {
+		eMap, err := ReadDotEnvFile(path)
+		if err != nil {
+			return err
+		}
+		return setter.SetEnvVarsLoaderFunc(EnvVarsMapLoaderFunc(eMap))
+	}
+}
+
+type dotEnvParser struct {
+	src  string
+	pos  int
+	line int
+}
+
+func (p *dotEnvParser) eof() bool {
+	return p.pos >= len(p.src)
+}
+
+func (p *dotEnvParser) peek() byte {
+	return p.src[p.pos]
+}
+
+func (p *dotEnvParser) advance() byte {
+	c := p.src[p.pos]
+	p.pos++
+	if c == '\n' {
+		p.line++
+	}
+	return c
+}
+
+func (p *dotEnvParser) skipBlanks() {
+	for !p.eof() && (p.peek() == ' ' || p.peek() == '\t') {
+		p.advance()
+	}
+}
+
+// skipLine skips everything up to and including the next newline.
+func (p *dotEnvParser) skipLine() {
+	for !p.eof() {
+		if p.advance() == '\n' {
+			return
+		}
+	}
+}
+
+// endOfEntry makes sure that only blanks or a comment follow a value.
+func (p *dotEnvParser) endOfEntry(line int) error {
+	p.skipBlanks()
+	if p.eof() {
+		return nil
+	}
+	switch p.peek() {
+	case '\n':
+		p.advance()
+		return nil
+	case '#':
+		p.skipLine()
+		return nil
+	}
+	return newDotEnvError(line, fmt.Sprintf("unexpected character %q after quoted value", p.peek()))
+}
+
+// next returns the next entry, ok is false once all the entries were read.
+func (p *dotEnvParser) next() (key, value string, ok bool, err error) {
+	for {
+		p.skipBlanks()
+		if p.eof() {
+			return "", "", false, nil
+		}
+		switch p.peek() {
+		case '\n':
+			p.advance()
+			continue
+		case '#':
+			p.skipLine()
+			continue
+		}
+		break
+	}
+
+	line := p.line
+	key = p.readKey()
+	if key == "export" {
+		p.skipBlanks()
+		if !p.eof() && p.peek() != '=' {
+			key = p.readKey()
+		}
+	}
+	if key == "" {
+		return "", "", false, newDotEnvError(line, "missing key")
+	}
+	if !isEnvNameStart(key[0]) {
+		return "", "", false, newDotEnvError(line, fmt.Sprintf("invalid key %q", key))
+	}
+
+	p.skipBlanks()
+	if p.eof() || p.peek() != '=' {
+		return "", "", false, newDotEnvError(line, fmt.Sprintf("missing = after key %q", key))
+	}
+	p.advance()
+	p.skipBlanks()
+
+	if p.eof() {
+		return key, "", true, nil
+	}
+	switch p.peek() {
+	case '\'':
+		value, err = p.readSingleQuoted(line)
+	case '"':
+		value, err = p.readDoubleQuoted(line)
+	default:
+		return key, p.readUnquoted(), true, nil
+	}
+	if err != nil {
+		return "", "", false, err
+	}
+	if err := p.endOfEntry(line); err != nil {
+		return "", "", false, err
+	}
+	return key, value, true, nil
+}
+
+func (p *dotEnvParser) readKey() string {
+	start := p.pos
+	for !p.eof() {
+		c := p.peek()
+		if !isEnvNameChar(c) && c != '.' && c != '-' {
+			break
+		}
+		p.advance()
+	}
+	return p.src[start:p.pos]
+}
+
+// readUnquoted reads up to the end of the line, an inline comment is only
+// recognized if the # is preceded by whitespace.
+func (p *dotEnvParser) readUnquoted() string {
+	start := p.pos
+	for !p.eof() && p.peek() != '\n' {
+		if p.peek() == '#' && p.pos > start && (p.src[p.pos-1] == ' ' || p.src[p.pos-1] == '\t') {
+			v := p.src[start:p.pos]
+			p.skipLine()
+			return strings.TrimSpace(v)
+		}
+		p.advance()
+	}
+	v := p.src[start:p.pos]
+	if !p.eof() {
+		p.advance()
+	}
+	return strings.TrimSpace(v)
+}
+
+func (p *dotEnvParser) readSingleQuoted(line int) (string, error) {
+	p.advance()
+	start := p.pos
+	for !p.eof() {
+		if p.peek() == '\'' {
+			v := p.src[start:p.pos]
+			p.advance()
+			return v, nil
+		}
+		p.advance()
+	}
+	return "", newDotEnvError(line, "unterminated single quoted value")
+}
+
+func (p *dotEnvParser) readDoubleQuoted(line int) (string, error) {
+	p.advance()
+	var b strings.Builder
+	for !p.eof() {
+		c := p.advance()
+		switch c {
+		case '"':
+			return b.String(), nil
+		case '\\':
+			if p.eof() {
+				return "", newDotEnvError(line, "unterminated double quoted value")
+			}
+			switch e := p.advance(); e {
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 't':
+				b.WriteByte('\t')
+			case '\\', '"':
+				b.WriteByte(e)
+			default:
+				b.WriteByte('\\')
+				b.WriteByte(e)
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", newDotEnvError(line, "unterminated double quoted value")
+}
+
+// formatDotEnvValue returns v formatted so that ParseDotEnv reads it back as
+// is, values that contain whitespace, quotes, backslashes or a # are double
+// quoted.
+func formatDotEnvValue(v string) string {
+	if !strings.ContainsAny(v, " \t\r\n#'\"\\") {
+		return v
+	}
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "\t", `\t`)
+	return `"` + r.Replace(v) + `"`
+}
@@ -0,0 +1,151 @@
+package envar
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/neumachen/errorx"
+)
+
+// envExampleIndex is used in place of the index of a slice of nested structs
+// when the struct is described for an example file, it is replaced by 0 when
+// writing and matches any index when checking.
+const envExampleIndex = "\x00"
+
+// WriteEnvExample writes a commented .env.example for the struct, or pointer to
+// struct, v to w. Each key is set to its default value and is preceded by its
+// description, whether it is required and its allowed values. The first
+// element is used for a slice of nested structs, e.g. SERVERS_0_HOST.
+func WriteEnvExample(w io.Writer, v interface{}, setterFuncs ...ParserCtxFuncSetter) error {
+	docs, err := describeStruct(v, envExampleIndex, setterFuncs...)
+	if err != nil {
+		return err
+	}
+
+	b := strings.Builder{}
+	for i := range docs {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		if docs[i].Description != "" {
+			b.WriteString("# " + docs[i].Description + "\n")
+		}
+		if docs[i].Required {
+			b.WriteString("# required\n")
+		}
+		if len(docs[i].AllowedValues) > 0 {
+			b.WriteString("# allowed values: " + strings.Join(docs[i].AllowedValues, ", ") + "\n")
+		}
+		key := strings.ReplaceAll(docs[i].EnvKey, envExampleIndex, "0")
+		b.WriteString(key + "=" + formatDotEnvValue(docs[i].Default) + "\n")
+	}
+
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return errorx.New(err)
+	}
+	return nil
+}
+
+// EnvExampleChange is a key whose value in the example file differs from the
+// default value of the field.
+type EnvExampleChange struct {
+	Key      string
+	Expected string
+	Actual   string
+}
+
+// EnvExampleDiff is the difference between an example file and a struct.
+type EnvExampleDiff struct {
+	// Missing are the sorted env keys of the struct that are not in the
+	// example.
+	Missing []string
+	// Stale are the sorted keys of the example that are not used by the
+	// struct.
+	Stale []string
+	// Changed are the keys whose value is not the default value.
+	Changed []EnvExampleChange
+}
+
+// IsEmpty reports whether the example file matches the struct.
+func (d EnvExampleDiff) IsEmpty() bool {
+	return len(d.Missing) < 1 && len(d.Stale) < 1 && len(d.Changed) < 1
+}
+
+func (d EnvExampleDiff) String() string {
+	lines := make([]string, 0, len(d.Missing)+len(d.Stale)+len(d.Changed))
+	for i := range d.Missing {
+		lines = append(lines, "missing: "+d.Missing[i])
+	}
+	for i := range d.Stale {
+		lines = append(lines, "stale: "+d.Stale[i])
+	}
+	for i := range d.Changed {
+		lines = append(lines, fmt.Sprintf("changed: %s: expected %q but found %q", d.Changed[i].Key, d.Changed[i].Expected, d.Changed[i].Actual))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// CheckEnvExample compares the example read from r with the one that
+// WriteEnvExample would write for v. The keys of any element of a slice of
+// nested structs are accepted, e.g. SERVERS_1_HOST.
+func CheckEnvExample(r io.Reader, v interface{}, setterFuncs ...ParserCtxFuncSetter) (EnvExampleDiff, error) {
+	diff := EnvExampleDiff{}
+
+	example, err := ParseDotEnv(r)
+	if err != nil {
+		return diff, err
+	}
+	docs, err := describeStruct(v, envExampleIndex, setterFuncs...)
+	if err != nil {
+		return diff, err
+	}
+
+	used := make(map[string]bool, len(example))
+	for i := range docs {
+		pattern := regexp.MustCompile("^" + strings.ReplaceAll(regexp.QuoteMeta(docs[i].EnvKey), envExampleIndex, "[0-9]+") + "$")
+		found := false
+		for key, value := range example {
+			if !pattern.MatchString(key) {
+				continue
+			}
+			found = true
+			used[key] = true
+			if value != docs[i].Default {
+				diff.Changed = append(diff.Changed, EnvExampleChange{
+					Key:      key,
+					Expected: docs[i].Default,
+					Actual:   value,
+				})
+			}
+		}
+		if !found {
+			diff.Missing = append(diff.Missing, strings.ReplaceAll(docs[i].EnvKey, envExampleIndex, "0"))
+		}
+	}
+	for key := range example {
+		if !used[key] {
+			diff.Stale = append(diff.Stale, key)
+		}
+	}
+
+	sort.Strings(diff.Missing)
+	sort.Strings(diff.Stale)
+	sort.Slice(diff.Changed, func(i, j int) bool {
+		return diff.Changed[i].Key < diff.Changed[j].Key
+	})
+	return diff, nil
+}
+
+// CheckEnvExampleFile is CheckEnvExample for the example file found at path.
+func CheckEnvExampleFile(path string, v interface{}, setterFuncs ...ParserCtxFuncSetter) (EnvExampleDiff, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return EnvExampleDiff{}, errorx.New(err)
+	}
+	defer f.Close()
+	return CheckEnvExample(f, v, setterFuncs...)
+}
@@ -0,0 +1,75 @@
+package envar
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type exampleServer struct {
+	Host string `env:"HOST,validate=required"`
+}
+
+type exampleConfig struct {
+	Name    string          `env:"NAME,validate=required,desc=service name"`
+	Greet   string          `env:"GREET,default=hello world"`
+	Debug   bool            `env:"DEBUG,default=false,desc=enable debug logs"`
+	Servers []exampleServer `env:"SERVERS,nested"`
+}
+
+func TestWriteEnvExample(t *testing.T) {
+	b := bytes.Buffer{}
+	require.NoError(t, WriteEnvExample(&b, exampleConfig{}, SetEnvPrefix("APP")))
+	require.Equal(t, strings.Join([]string{
+		"# service name",
+		"# required",
+		"APP_NAME=",
+		"",
+		`APP_GREET="hello world"`,
+		"",
+		"# enable debug logs",
+		"# allowed values: true, false",
+		"APP_DEBUG=false",
+		"",
+		"# required",
+		"APP_SERVERS_0_HOST=",
+		"",
+	}, "\n"), b.String())
+
+	diff, err := CheckEnvExample(&b, exampleConfig{}, SetEnvPrefix("APP"))
+	require.NoError(t, err)
+	require.True(t, diff.IsEmpty(), diff.String())
+}
+
+func TestCheckEnvExample(t *testing.T) {
+	example := strings.Join([]string{
+		"APP_NAME=",
+		"APP_GREET=hi",
+		"APP_SERVERS_0_HOST=",
+		"APP_SERVERS_1_HOST=",
+		"APP_REMOVED=1",
+	}, "\n")
+
+	diff, err := CheckEnvExample(strings.NewReader(example), exampleConfig{}, SetEnvPrefix("APP"))
+	require.NoError(t, err)
+	require.False(t, diff.IsEmpty())
+	require.Equal(t, EnvExampleDiff{
+		Missing: []string{"APP_DEBUG"},
+		Stale:   []string{"APP_REMOVED"},
+		Changed: []EnvExampleChange{
+			{Key: "APP_GREET", Expected: "hello world", Actual: "hi"},
+		},
+	}, diff)
+	require.Equal(t, strings.Join([]string{
+		"missing: APP_DEBUG",
+		"stale: APP_REMOVED",
+		`changed: APP_GREET: expected "hello world" but found "hi"`,
+	}, "\n"), diff.String())
+
+	// the keys are sorted rather than in the order of the fields
+	diff, err = CheckEnvExample(strings.NewReader(""), exampleConfig{}, SetEnvPrefix("APP"))
+	require.NoError(t, err)
+	require.Equal(t, []string{"APP_DEBUG", "APP_GREET", "APP_NAME", "APP_SERVERS_0_HOST"}, diff.Missing)
+}
@@ -0,0 +1,125 @@
+package envar
+
+import (
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/neumachen/errorx"
+)
+
+// EnvVarsSourceFunc loads the EnvVarsMap of a single source that is part of an
+// EnvVarsLoaderChain. Unlike an EnvVarsLoaderFunc it can fail, e.g. when a
+// dotenv file is malformed.
+type EnvVarsSourceFunc func() (EnvVarsMap, error)
+
+// DotEnvFileSource returns an EnvVarsSourceFunc that reads the dotenv file
+// found at path. It is an error if the file does not exist.
+func DotEnvFileSource(path string) EnvVarsSourceFunc {
+	return func() (EnvVarsMap, error) {
+		return ReadDotEnvFile(path)
+	}
+}
+
+// OptionalDotEnvFileSource is like DotEnvFileSource but an empty EnvVarsMap is
+// loaded when the file does not exist, which is useful for files like
+// .env.local that are not always present.
+func OptionalDotEnvFileSource(path string) EnvVarsSourceFunc {
+	return func() (EnvVarsMap, error) {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			return EnvVarsMap{}, nil
+		}
+		return ReadDotEnvFile(path)
+	}
+}
+
+type envVarsSource struct {
+	name string
+	fn   EnvVarsSourceFunc
+}
+
+// EnvVarsLoaderChain merges the EnvVarsMap of several sources into one.
+// Sources are added from the lowest to the highest priority, a key that is
+// defined by more than one source takes the value of the source that was added
+// last. The name of the source that supplied each key is recorded and can be
+// retrieved with GetSource after Load was called.
+type EnvVarsLoaderChain struct {
+	sources []envVarsSource
+	mu      sync.RWMutex
+	origins map[string]string
+}
+
+// NewEnvVarsLoaderChain returns an empty EnvVarsLoaderChain.
+func NewEnvVarsLoaderChain() *EnvVarsLoaderChain {
+	return &EnvVarsLoaderChain{}
+}
+
+// Add adds a source with a higher priority than the ones already added.
+func (c *EnvVarsLoaderChain) Add(name string, fn EnvVarsSourceFunc) *EnvVarsLoaderChain {
+	c.sources = append(c.sources, envVarsSource{name: name, fn: fn})
+	return c
+}
+
+// AddLoaderFunc adds an EnvVarsLoaderFunc as a source with a higher priority
+// than the ones already added, e.g. LoadEnvVars for the environment variables
+// of the process.
+func (c *EnvVarsLoaderChain) AddLoaderFunc(name string, fn EnvVarsLoaderFunc) *EnvVarsLoaderChain {
+	return c.Add(name, func() (EnvVarsMap, error) {
+		return fn(), nil
+	})
+}
+
+// Load loads every source and merges them into a single EnvVarsMap.
+func (c *EnvVarsLoaderChain) Load() (EnvVarsMap, error) {
+	eMap := make(EnvVarsMap)
+	origins := make(map[string]string)
+	for i := range c.sources {
+		sMap, err := c.sources[i].fn()
+		if err != nil {
+			return nil, errorx.New(fmt.Sprintf("env: unable to load source %s: %v", c.sources[i].name, err))
+		}
+		for k, v := range sMap {
+			eMap.Set(k, v)
+			origins[k] = c.sources[i].name
+		}
+	}
+
+	c.mu.Lock()
+	c.origins = origins
+	c.mu.Unlock()
+
+	return eMap, nil
+}
+
+// GetSource returns the name of the source that supplied the key the last time
+// Load was called.
+func (c *EnvVarsLoaderChain) GetSource(key string) (string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	name, ok := c.origins[key]
+	return name, ok
+}
+
+// GetSources returns the name of the source that supplied each key the last
+// time Load was called.
+func (c *EnvVarsLoaderChain) GetSources() map[string]string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	origins := make(map[string]string, len(c.origins))
+	for k, v := range c.origins {
+		origins[k] = v
+	}
+	return origins
+}
+
+// SetEnvVarsLoaderChain loads the chain and uses the merged EnvVarsMap instead
+// of the environment variables of the process.
+func SetEnvVarsLoaderChain(chain *EnvVarsLoaderChain) ParserCtxFuncSetter {
+	return func(setter ParserCtxSetter) error {
+		eMap, err := chain.Load()
+		if err != nil {
+			return err
+		}
+		return setter.SetEnvVarsLoaderFunc(EnvVarsMapLoaderFunc(eMap))
+	}
+}
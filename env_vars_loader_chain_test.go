@@ -0,0 +1,63 @@
+package envar
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnvVarsLoaderChain(t *testing.T) {
+	dir := t.TempDir()
+	defaultsPath := filepath.Join(dir, "defaults.env")
+	dotEnvPath := filepath.Join(dir, ".env")
+	require.NoError(t, os.WriteFile(defaultsPath, []byte("HOST=0.0.0.0\nPORT=80\nLOG_LEVEL=info\n"), 0o600))
+	require.NoError(t, os.WriteFile(dotEnvPath, []byte("PORT=8080\n"), 0o600))
+
+	chain := NewEnvVarsLoaderChain().
+		Add("defaults", DotEnvFileSource(defaultsPath)).
+		Add(".env", DotEnvFileSource(dotEnvPath)).
+		Add(".env.local", OptionalDotEnvFileSource(filepath.Join(dir, ".env.local"))).
+		AddLoaderFunc("process", func() EnvVarsMap {
+			return EnvVarsMap{"LOG_LEVEL": "debug"}
+		})
+
+	type config struct {
+		Host     string `env:"HOST"`
+		Port     int    `env:"PORT"`
+		LogLevel string `env:"LOG_LEVEL"`
+	}
+
+	cfg := config{}
+	parserCtx, err := Parse(&cfg, SetEnvVarsLoaderChain(chain))
+	require.NoError(t, err)
+	require.Equal(t, config{Host: "0.0.0.0", Port: 8080, LogLevel: "debug"}, cfg)
+	require.Equal(t, 3, parserCtx.GetEnvVarsMap().GetLength())
+
+	source, ok := chain.GetSource("PORT")
+	require.True(t, ok)
+	require.Equal(t, ".env", source)
+	require.Equal(t, map[string]string{
+		"HOST":      "defaults",
+		"PORT":      ".env",
+		"LOG_LEVEL": "process",
+	}, chain.GetSources())
+
+	_, ok = chain.GetSource("MISSING")
+	require.False(t, ok)
+
+	failing := NewEnvVarsLoaderChain().
+		Add("defaults", DotEnvFileSource(defaultsPath)).
+		Add("broken", func() (EnvVarsMap, error) {
+			return nil, errors.New("boom")
+		})
+	_, err = Parse(&config{}, SetEnvVarsLoaderChain(failing))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "source broken: boom")
+
+	missing := NewEnvVarsLoaderChain().Add(".env", DotEnvFileSource(filepath.Join(dir, "missing.env")))
+	_, err = missing.Load()
+	require.Error(t, err)
+}
@@ -2,11 +2,18 @@ package envar
 
 import (
 	"os"
+	"sort"
 	"strings"
 )
 
 type EnvVarsLoaderFunc func() EnvVarsMap
 
+// LoadEnvVars is the default EnvVarsLoaderFunc, it loads the environment
+// variables of the process.
+func LoadEnvVars() EnvVarsMap {
+	return loadEnvVarsToMap()
+}
+
 func loadEnvVarsToMap() EnvVarsMap {
 	envStrs := os.Environ()
 	if len(envStrs) < 1 {
@@ -20,6 +27,14 @@ func loadEnvVarsToMap() EnvVarsMap {
 	return eMap
 }
 
+// EnvVarsMapLoaderFunc returns an EnvVarsLoaderFunc that loads the given
+// eMap, e.g. the result of ReadDotEnvFile.
+func EnvVarsMapLoaderFunc(eMap EnvVarsMap) EnvVarsLoaderFunc {
+	return func() EnvVarsMap {
+		return eMap
+	}
+}
+
 type EnvVarsMap map[string]string
 
 func (e EnvVarsMap) GetLength() int {
@@ -34,3 +49,19 @@ func (e EnvVarsMap) Get(key string) (string, bool) {
 func (e EnvVarsMap) Set(key, value string) {
 	e[key] = value
 }
+
+// Environ returns the env vars as KEY=VALUE pairs sorted by key, which is the
+// format of os.Environ and exec.Cmd.Env.
+func (e EnvVarsMap) Environ() []string {
+	keys := make([]string, 0, len(e))
+	for k := range e {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	environ := make([]string, len(keys))
+	for i := range keys {
+		environ[i] = keys[i] + "=" + e[keys[i]]
+	}
+	return environ
+}
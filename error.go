@@ -1,29 +1,185 @@
 package envar
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
 )
 
-func newParseError(sField reflect.StructField, err error) error {
+func newParseError(pField *parsedField, value string, err error) error {
 	if err == nil {
 		return nil
 	}
-	return parseError{
-		sf:  sField,
-		err: err,
+	return &ParseError{
+		FieldName: pField.GetStructField().Name,
+		FieldType: pField.GetStructField().Type,
+		Redacted:  pField.redactErrors(),
+		value:     value,
+		err:       err,
 	}
 }
 
-type parseError struct {
-	sf  reflect.StructField
-	err error
+// ParseError is the error of a value that could not be converted to the type
+// of its field. The value is redacted from the message of sensitive fields, or
+// of every field when SetRedactErrors is enabled, Value and Unredacted give
+// access to the original.
+type ParseError struct {
+	// FieldName is the name of the struct field.
+	FieldName string
+	// FieldType is the type the value was converted to.
+	FieldType reflect.Type
+	// Redacted reports whether the value is redacted from the message.
+	Redacted bool
+	value    string
+	err      error
 }
 
-func (e parseError) Error() string {
-	return fmt.Sprintf(`env: parse error on field "%s" of type "%s": %v`, e.sf.Name, e.sf.Type, e.err)
+func (e *ParseError) Error() string {
+	return fmt.Sprintf(`env: parse error on field "%s" of type "%s": %v`, e.FieldName, e.FieldType, e.Unwrap())
+}
+
+// Unwrap returns the error of the conversion, its message is redacted as well
+// but errors.Is and errors.As still match the original.
+func (e *ParseError) Unwrap() error {
+	if e.Redacted {
+		return redactedError{err: e.err, value: e.value}
+	}
+	return e.err
+}
+
+// Value returns the value that could not be converted, it is never redacted.
+func (e *ParseError) Value() string {
+	return e.value
+}
+
+// Unredacted returns the error of the conversion as is, its message may
+// contain the value.
+func (e *ParseError) Unredacted() error {
+	return e.err
+}
+
+// redactedError hides the value in the message of err.
+type redactedError struct {
+	err   error
+	value string
+}
+
+func (e redactedError) Error() string {
+	return redactString(e.err.Error(), e.value)
+}
+
+func (e redactedError) Is(target error) bool {
+	return errors.Is(e.err, target)
+}
+
+func (e redactedError) As(target interface{}) bool {
+	return errors.As(e.err, target)
+}
+
+// redactString replaces the value in s with RedactedValue where s contains it
+// quoted, e.g. by strconv, or as a whole word. The value is left alone where
+// it is only part of a word so that a short value, e.g. "e", doesn't mangle
+// the message.
+func redactString(s, value string) string {
+	if value == "" {
+		return s
+	}
+	s = strings.ReplaceAll(s, strconv.Quote(value), strconv.Quote(RedactedValue))
+
+	b := strings.Builder{}
+	for {
+		i := strings.Index(s, value)
+		if i < 0 {
+			break
+		}
+		end := i + len(value)
+		if isWholeWord(s[:i], value, s[end:]) {
+			b.WriteString(s[:i])
+			b.WriteString(RedactedValue)
+		} else {
+			b.WriteString(s[:end])
+		}
+		s = s[end:]
+	}
+	b.WriteString(s)
+	return b.String()
+}
+
+// isWholeWord reports whether value, found between before and after, is not
+// part of a longer word.
+func isWholeWord(before, value, after string) bool {
+	first, _ := utf8.DecodeRuneInString(value)
+	last, _ := utf8.DecodeLastRuneInString(value)
+	prev, _ := utf8.DecodeLastRuneInString(before)
+	next, _ := utf8.DecodeRuneInString(after)
+	return !(before != "" && isWordRune(first) && isWordRune(prev)) &&
+		!(after != "" && isWordRune(last) && isWordRune(next))
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
 }
 
 func newNoParserError(sf reflect.StructField) error {
 	return fmt.Errorf(`env: no parser found for field "%s" of type "%s"`, sf.Name, sf.Type)
 }
+
+// newFieldError attributes err to the field found at fieldPath. Errors that
+// are already attributed to a field, e.g. the ones of a nested struct, are
+// returned as is.
+func newFieldError(fieldPath, envKey string, err error) error {
+	var fieldErr *FieldError
+	if errors.As(err, &fieldErr) {
+		return err
+	}
+	return &FieldError{
+		FieldPath: fieldPath,
+		EnvKey:    envKey,
+		Err:       err,
+	}
+}
+
+// FieldError is the error of a single struct field.
+type FieldError struct {
+	// FieldPath is the path of the field starting at the struct passed to
+	// Parse, e.g. Config.DB.Host
+	FieldPath string
+	// EnvKey is the env key of the field, it is empty if the error happened
+	// before the env key could be determined.
+	EnvKey string
+	Err    error
+}
+
+func (e *FieldError) Error() string {
+	if e.EnvKey == "" {
+		return fmt.Sprintf("%s: %v", e.FieldPath, e.Err)
+	}
+	return fmt.Sprintf("%s (%s): %v", e.FieldPath, e.EnvKey, e.Err)
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// ParseErrors holds the errors of every field that failed to parse. It can be
+// inspected using errors.Is and errors.As.
+type ParseErrors []error
+
+func (e ParseErrors) Error() string {
+	if len(e) == 1 {
+		return e[0].Error()
+	}
+	msgs := make([]string, len(e))
+	for i := range e {
+		msgs[i] = "\t* " + e[i].Error()
+	}
+	return fmt.Sprintf("env: %d errors occurred:\n%s", len(e), strings.Join(msgs, "\n"))
+}
+
+func (e ParseErrors) Unwrap() []error {
+	return e
+}
@@ -0,0 +1,76 @@
+package envar
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type aggregateNested struct {
+	Port int `env:"NESTED_PORT"`
+}
+
+type aggregateConfig struct {
+	Int     int             `env:"INT"`
+	Bool    bool            `env:"BOOL"`
+	Unknown string          `env:"UNKNOWN,nope"`
+	Valid   string          `env:"VALID"`
+	Nested  aggregateNested `env:",nested"`
+}
+
+func TestParse_AggregateErrors(t *testing.T) {
+	eMap := EnvVarsMap{
+		"INT":         "not-an-int",
+		"BOOL":        "not-a-bool",
+		"VALID":       "valid",
+		"NESTED_PORT": "not-a-port",
+	}
+
+	t.Run("aggregated", func(t *testing.T) {
+		cfg := aggregateConfig{}
+		parserCtx, err := Parse(&cfg, SetEnvVarsLoaderFunc(func() EnvVarsMap { return eMap }))
+		require.Error(t, err)
+		require.Nil(t, parserCtx)
+		require.Equal(t, "valid", cfg.Valid)
+
+		var parseErrs ParseErrors
+		require.ErrorAs(t, err, &parseErrs)
+		require.Len(t, parseErrs, 4)
+
+		paths := make([]string, 0, len(parseErrs))
+		for i := range parseErrs {
+			var fieldErr *FieldError
+			require.ErrorAs(t, parseErrs[i], &fieldErr)
+			paths = append(paths, fieldErr.FieldPath)
+		}
+		require.Equal(t, []string{
+			"aggregateConfig.Int",
+			"aggregateConfig.Bool",
+			"aggregateConfig.Unknown",
+			"aggregateConfig.Nested.Port",
+		}, paths)
+
+		require.True(t, errors.Is(err, strconv.ErrSyntax))
+		require.Contains(t, err.Error(), "4 errors occurred")
+		require.Contains(t, err.Error(), "aggregateConfig.Nested.Port (NESTED_PORT)")
+	})
+
+	t.Run("fail fast", func(t *testing.T) {
+		cfg := aggregateConfig{}
+		parserCtx, err := Parse(
+			&cfg,
+			SetEnvVarsLoaderFunc(func() EnvVarsMap { return eMap }),
+			SetFailFast(true),
+		)
+		require.Error(t, err)
+		require.Nil(t, parserCtx)
+		require.Empty(t, cfg.Valid)
+
+		var fieldErr *FieldError
+		require.ErrorAs(t, err, &fieldErr)
+		require.Equal(t, "aggregateConfig.Int", fieldErr.FieldPath)
+		require.Equal(t, "INT", fieldErr.EnvKey)
+	})
+}
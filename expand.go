@@ -0,0 +1,188 @@
+package envar
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/neumachen/errorx"
+)
+
+// expander interpolates shell-style variable references against an
+// EnvVarsMap. Referenced values are expanded recursively, stack keeps track of
+// the variables currently being expanded so that cycles can be detected.
+type expander struct {
+	envVarsMap EnvVarsMap
+	stack      []string
+}
+
+// expandSyntaxError is the error of a malformed variable reference, expr is
+// the part of the value that is reported.
+type expandSyntaxError struct {
+	msg  string
+	expr string
+}
+
+func (e *expandSyntaxError) Error() string {
+	return fmt.Sprintf("env: %s: %s", e.msg, e.expr)
+}
+
+// expandEnvValue expands $VAR, ${VAR}, ${VAR:-default}, ${VAR-default},
+// ${VAR:?message} and ${VAR?message} references in value using eMap. A $$ is
+// replaced by a single $. The keys passed in as visiting are treated as
+// already being expanded, which is used to detect a variable that references
+// itself.
+func expandEnvValue(value string, eMap EnvVarsMap, visiting ...string) (string, error) {
+	e := &expander{
+		envVarsMap: eMap,
+		stack:      visiting,
+	}
+	return e.expand(value)
+}
+
+func (e *expander) expand(value string) (string, error) {
+	if !strings.Contains(value, "$") {
+		return value, nil
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] != '$' || i+1 >= len(value) {
+			b.WriteByte(value[i])
+			continue
+		}
+
+		switch c := value[i+1]; {
+		case c == '$':
+			b.WriteByte('$')
+			i++
+		case c == '{':
+			end := matchingBrace(value, i+1)
+			if end < 0 {
+				return "", errorx.New(&expandSyntaxError{msg: "unterminated variable reference", expr: value[i:]})
+			}
+			v, err := e.expandBraced(value[i+2 : end])
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(v)
+			i = end
+		case isEnvNameStart(c):
+			j := i + 1
+			for j < len(value) && isEnvNameChar(value[j]) {
+				j++
+			}
+			v, _, err := e.lookup(value[i+1 : j])
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(v)
+			i = j - 1
+		default:
+			b.WriteByte('$')
+		}
+	}
+	return b.String(), nil
+}
+
+// expandBraced expands the expression found between ${ and }.
+func (e *expander) expandBraced(expr string) (string, error) {
+	n := 0
+	for n < len(expr) && isEnvNameChar(expr[n]) {
+		n++
+	}
+	name, op := expr[:n], expr[n:]
+	if n < 1 || !isEnvNameStart(name[0]) {
+		return "", errorx.New(&expandSyntaxError{msg: "bad substitution", expr: "${" + expr + "}"})
+	}
+
+	v, found, err := e.lookup(name)
+	if err != nil {
+		return "", err
+	}
+	if op == "" {
+		return v, nil
+	}
+
+	// the colon variants treat an empty value the same as an unset one
+	unset := !found
+	if strings.HasPrefix(op, ":") {
+		unset = !found || v == ""
+		op = op[1:]
+	}
+	if op == "" {
+		return "", errorx.New(&expandSyntaxError{msg: "bad substitution", expr: "${" + expr + "}"})
+	}
+
+	switch op[0] {
+	case '-':
+		if !unset {
+			return v, nil
+		}
+		return e.expand(op[1:])
+	case '?':
+		if !unset {
+			return v, nil
+		}
+		msg, err := e.expand(op[1:])
+		if err != nil {
+			return "", err
+		}
+		if msg == "" {
+			msg = "parameter null or not set"
+		}
+		return "", errorx.New(fmt.Sprintf("env: %s: %s", name, msg))
+	}
+	return "", errorx.New(&expandSyntaxError{msg: "bad substitution", expr: "${" + expr + "}"})
+}
+
+// lookup returns the expanded value of the variable name and whether it was
+// found in the EnvVarsMap.
+func (e *expander) lookup(name string) (string, bool, error) {
+	for i := range e.stack {
+		if e.stack[i] == name {
+			chain := append(append([]string{}, e.stack[i:]...), name)
+			return "", false, errorx.New(fmt.Sprintf("env: cyclic variable expansion: %s", strings.Join(chain, " -> ")))
+		}
+	}
+
+	v, ok := e.envVarsMap.Get(name)
+	if !ok {
+		return "", false, nil
+	}
+
+	e.stack = append(e.stack, name)
+	defer func() { e.stack = e.stack[:len(e.stack)-1] }()
+
+	v, err := e.expand(v)
+	if err != nil {
+		return "", false, err
+	}
+	return v, true, nil
+}
+
+// matchingBrace returns the index of the } that closes the { found at start,
+// taking nested ${...} references into account. It returns -1 if the brace is
+// never closed.
+func matchingBrace(value string, start int) int {
+	depth := 0
+	for i := start; i < len(value); i++ {
+		switch value[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func isEnvNameStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isEnvNameChar(c byte) bool {
+	return isEnvNameStart(c) || (c >= '0' && c <= '9')
+}
@@ -0,0 +1,123 @@
+package envar
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpandEnvValue(t *testing.T) {
+	eMap := EnvVarsMap{
+		"HOST":  "localhost",
+		"PORT":  "5432",
+		"EMPTY": "",
+		"ADDR":  "${HOST}:${PORT}",
+		"LOOP1": "$LOOP2",
+		"LOOP2": "${LOOP1}",
+		"SELF":  "$SELF",
+	}
+
+	testData := []struct {
+		value    string
+		expected string
+	}{
+		{value: "no references", expected: "no references"},
+		{value: "$HOST:$PORT", expected: "localhost:5432"},
+		{value: "${HOST}_db", expected: "localhost_db"},
+		{value: "tcp://$ADDR", expected: "tcp://localhost:5432"},
+		{value: "$MISSING", expected: ""},
+		{value: "${MISSING:-fallback}", expected: "fallback"},
+		{value: "${EMPTY:-fallback}", expected: "fallback"},
+		{value: "${EMPTY-fallback}", expected: ""},
+		{value: "${MISSING-$HOST}", expected: "localhost"},
+		{value: "${MISSING:-${HOST}:${PORT}}", expected: "localhost:5432"},
+		{value: "${HOST:?host is required}", expected: "localhost"},
+		{value: "cost: $$5", expected: "cost: $5"},
+		{value: "$$HOST", expected: "$HOST"},
+		{value: "trailing $", expected: "trailing $"},
+		{value: "$1", expected: "$1"},
+	}
+	for i := range testData {
+		v, err := expandEnvValue(testData[i].value, eMap)
+		require.NoError(t, err, testData[i].value)
+		require.Equal(t, testData[i].expected, v, testData[i].value)
+	}
+
+	errData := []struct {
+		value       string
+		errContains string
+	}{
+		{value: "${MISSING:?must be set}", errContains: "MISSING: must be set"},
+		{value: "${EMPTY:?}", errContains: "EMPTY: parameter null or not set"},
+		{value: "${HOST", errContains: "unterminated"},
+		{value: "${1HOST}", errContains: "bad substitution"},
+		{value: "${HOST:=x}", errContains: "bad substitution"},
+		{value: "$LOOP1", errContains: "LOOP1 -> LOOP2 -> LOOP1"},
+		{value: "$SELF", errContains: "SELF -> SELF"},
+	}
+	for i := range errData {
+		_, err := expandEnvValue(errData[i].value, eMap)
+		require.Error(t, err, errData[i].value)
+		require.Contains(t, err.Error(), errData[i].errContains)
+	}
+}
+
+func TestParse_Expand(t *testing.T) {
+	eMap := EnvVarsMap{
+		"DB_HOST": "db.internal",
+		"DB_PORT": "5432",
+		"DSN":     "postgres://${DB_HOST}:${DB_PORT}/app",
+		"LITERAL": "$DB_HOST",
+		"CYCLE":   "${CYCLE}",
+	}
+
+	t.Run("expanded", func(t *testing.T) {
+		type config struct {
+			DSN     string `env:"DSN,expand"`
+			Literal string `env:"LITERAL"`
+			Default string `env:"MISSING,expand,default=$DB_HOST"`
+		}
+
+		cfg := config{}
+		_, err := Parse(&cfg, SetEnvVarsLoaderFunc(func() EnvVarsMap { return eMap }))
+		require.NoError(t, err)
+		require.Equal(t, "postgres://db.internal:5432/app", cfg.DSN)
+		require.Equal(t, "$DB_HOST", cfg.Literal)
+		require.Equal(t, "db.internal", cfg.Default)
+	})
+
+	t.Run("cycle", func(t *testing.T) {
+		type config struct {
+			Cycle string `env:"CYCLE,expand"`
+		}
+
+		cfg := config{}
+		_, err := Parse(&cfg, SetEnvVarsLoaderFunc(func() EnvVarsMap { return eMap }))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "CYCLE -> CYCLE")
+	})
+}
+
+func TestMarshal_Expand(t *testing.T) {
+	type config struct {
+		DSN string `env:"DSN,expand"`
+	}
+
+	cfg := config{}
+	_, err := Parse(&cfg, SetEnvVarsLoaderFunc(EnvVarsMapLoaderFunc(EnvVarsMap{
+		"HOST": "h",
+		"DSN":  "pg://$HOST/a$$b",
+	})))
+	require.NoError(t, err)
+	require.Equal(t, "pg://h/a$b", cfg.DSN)
+
+	// the $ of the value is escaped so that it's not expanded again
+	eMap, err := Marshal(cfg)
+	require.NoError(t, err)
+	require.Equal(t, EnvVarsMap{"DSN": "pg://h/a$$b"}, eMap)
+
+	roundTrip := config{}
+	_, err = Parse(&roundTrip, SetEnvVarsLoaderFunc(EnvVarsMapLoaderFunc(eMap)))
+	require.NoError(t, err)
+	require.Equal(t, cfg, roundTrip)
+}
@@ -0,0 +1,87 @@
+package envar
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/neumachen/errorx"
+	"github.com/neumachen/gobag"
+)
+
+// DefaultFileEnvSuffix is the suffix of the env key that holds the path of a
+// file containing the value, e.g. DB_PASSWORD_FILE for DB_PASSWORD, see
+// SetFileEnvSuffix.
+const DefaultFileEnvSuffix = "_FILE"
+
+// DefaultFileMaxSize is the maximum size in bytes of a file that is read for
+// the value of a field.
+const DefaultFileMaxSize = 1 << 20
+
+// readFile replaces the env value with the contents of a file. The path is the
+// value of the env var when the field has the file tag option, otherwise it is
+// the value of the env key with the file env suffix, if the suffix is set.
+func (p *parsedField) readFile(parserCtx *ParserCtx) error {
+	path := ""
+	switch {
+	case p.tagOpts.getFileKey():
+		path = p.getFieldValue()
+	case parserCtx.GetFileEnvSuffix() != "":
+		fileEnvKey := p.GetEnvKey() + parserCtx.GetFileEnvSuffix()
+		v, ok := parserCtx.GetEnvVarsMap().Get(fileEnvKey)
+		if !ok || gobag.StringIsEmpty(v) {
+			return nil
+		}
+		if !gobag.StringIsEmpty(p.GetEnvValue()) {
+			return errorx.New(fmt.Sprintf("env: both %s and %s are set", p.GetEnvKey(), fileEnvKey))
+		}
+		path = v
+		p.fileEnvKey = fileEnvKey
+	}
+	if gobag.StringIsEmpty(path) {
+		return nil
+	}
+
+	contents, err := readFileContents(path, parserCtx.GetFileMaxSize())
+	if err != nil {
+		return errorx.New(fmt.Sprintf("env: unable to read the file of env key %s: %v", p.GetEnvKey(), err))
+	}
+	p.envValue = contents
+	p.keyFound = true
+	// the default, if any, was either the path or is replaced by the file
+	delete(p.tagOpts, tagOptsDefaultKey)
+	return nil
+}
+
+// readFileContents returns the contents of the file found at path with the
+// leading and trailing whitespace removed. It is an error if the file is
+// larger than maxSize bytes, unless maxSize is 0.
+func readFileContents(path string, maxSize int64) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	fileInfo, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+	if fileInfo.IsDir() {
+		return "", errorx.New(fmt.Sprintf("%s is a directory", path))
+	}
+
+	var r io.Reader = f
+	if maxSize > 0 {
+		r = io.LimitReader(f, maxSize+1)
+	}
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return "", err
+	}
+	if maxSize > 0 && int64(len(b)) > maxSize {
+		return "", errorx.New(fmt.Sprintf("%s exceeds the maximum size of %d bytes", path, maxSize))
+	}
+	return strings.TrimSpace(string(b)), nil
+}
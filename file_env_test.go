@@ -0,0 +1,155 @@
+package envar
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse_FileTagOption(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "password")
+	require.NoError(t, os.WriteFile(path, []byte("  s3cr3t\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "token"), []byte("tok\n"), 0o600))
+
+	// the default path is relative to the working directory
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+
+	type config struct {
+		Password string `env:"PASSWORD,file,validate=required"`
+		Token    string `env:"TOKEN,file,default=token"`
+		Empty    string `env:"EMPTY,file"`
+	}
+
+	cfg := config{}
+	_, err = Parse(&cfg, SetEnvVarsLoaderFunc(EnvVarsMapLoaderFunc(EnvVarsMap{
+		"PASSWORD": path,
+	})))
+	require.NoError(t, err)
+	require.Equal(t, "s3cr3t", cfg.Password)
+	require.Equal(t, "tok", cfg.Token)
+	require.Equal(t, "", cfg.Empty)
+
+	_, err = Parse(&config{}, SetEnvVarsLoaderFunc(EnvVarsMapLoaderFunc(EnvVarsMap{
+		"PASSWORD": filepath.Join(dir, "missing"),
+	})))
+	require.Error(t, err)
+	fieldErr := &FieldError{}
+	require.ErrorAs(t, err, &fieldErr)
+	require.Equal(t, "PASSWORD", fieldErr.EnvKey)
+	require.Contains(t, err.Error(), "unable to read the file of env key PASSWORD")
+}
+
+func TestParse_FileEnvSuffix(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "password")
+	require.NoError(t, os.WriteFile(path, []byte("s3cr3t\n"), 0o600))
+
+	type config struct {
+		Password string `env:"DB_PASSWORD,validate=required"`
+		Port     int    `env:"DB_PORT"`
+	}
+
+	cfg := config{}
+	_, err := Parse(
+		&cfg,
+		SetFileEnvSuffix(DefaultFileEnvSuffix),
+		SetEnvVarsLoaderFunc(EnvVarsMapLoaderFunc(EnvVarsMap{
+			"DB_PASSWORD_FILE": path,
+			"DB_PORT":          "5432",
+		})),
+	)
+	require.NoError(t, err)
+	require.Equal(t, "s3cr3t", cfg.Password)
+	require.Equal(t, 5432, cfg.Port)
+
+	// disabled by default
+	_, err = Parse(&config{}, SetEnvVarsLoaderFunc(EnvVarsMapLoaderFunc(EnvVarsMap{
+		"DB_PASSWORD_FILE": path,
+	})))
+	validationErrs := &ValidationErrors{}
+	require.ErrorAs(t, err, &validationErrs)
+
+	_, err = Parse(
+		&config{},
+		SetFileEnvSuffix(DefaultFileEnvSuffix),
+		SetEnvVarsLoaderFunc(EnvVarsMapLoaderFunc(EnvVarsMap{
+			"DB_PASSWORD":      "plain",
+			"DB_PASSWORD_FILE": path,
+		})),
+	)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "both DB_PASSWORD and DB_PASSWORD_FILE are set")
+}
+
+func TestParse_FileMaxSize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "big")
+	require.NoError(t, os.WriteFile(path, []byte(strings.Repeat("a", 32)), 0o600))
+
+	type config struct {
+		Value string `env:"VALUE,file"`
+	}
+	loader := SetEnvVarsLoaderFunc(EnvVarsMapLoaderFunc(EnvVarsMap{"VALUE": path}))
+
+	_, err := Parse(&config{}, loader, SetFileMaxSize(16))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "exceeds the maximum size of 16 bytes")
+
+	cfg := config{}
+	_, err = Parse(&cfg, loader, SetFileMaxSize(0))
+	require.NoError(t, err)
+	require.Equal(t, strings.Repeat("a", 32), cfg.Value)
+}
+
+func TestParse_FileEnvSuffix_Unset(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "password")
+	require.NoError(t, os.WriteFile(path, []byte("s3cr3t"), 0o600))
+	t.Setenv("UNSET_PASSWORD_FILE", path)
+
+	type config struct {
+		Password string `env:"UNSET_PASSWORD,unset"`
+	}
+
+	cfg := config{}
+	_, err := Parse(&cfg, SetFileEnvSuffix(DefaultFileEnvSuffix))
+	require.NoError(t, err)
+	require.Equal(t, "s3cr3t", cfg.Password)
+	_, ok := os.LookupEnv("UNSET_PASSWORD_FILE")
+	require.False(t, ok)
+}
+
+func TestMarshal_FileTagOption(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "password")
+	require.NoError(t, os.WriteFile(path, []byte("s3cret"), 0o600))
+
+	type config struct {
+		User     string `env:"USER"`
+		Password string `env:"PW,file"`
+	}
+
+	cfg := config{}
+	_, err := Parse(&cfg, SetEnvVarsLoaderFunc(EnvVarsMapLoaderFunc(EnvVarsMap{
+		"USER": "app",
+		"PW":   path,
+	})))
+	require.NoError(t, err)
+	require.Equal(t, "s3cret", cfg.Password)
+
+	// the contents of the file would be read as a path
+	eMap, err := Marshal(cfg)
+	require.NoError(t, err)
+	require.Equal(t, EnvVarsMap{"USER": "app"}, eMap)
+
+	// the caller supplies the path
+	eMap.Set("PW", path)
+	roundTrip := config{}
+	_, err = Parse(&roundTrip, SetEnvVarsLoaderFunc(EnvVarsMapLoaderFunc(eMap)))
+	require.NoError(t, err)
+	require.Equal(t, cfg, roundTrip)
+}
@@ -0,0 +1,131 @@
+package envar
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/neumachen/errorx"
+	"github.com/neumachen/gobag"
+)
+
+// DefaultEnvMapKeyValueDelim is the delimiter between the key and the value of
+// a map entry, e.g. k1:v1,k2:v2
+const DefaultEnvMapKeyValueDelim = ":"
+
+// handleMap parses a value like k1:v1,k2:v2 into a map field. The entries are
+// split using the sep tag option, or the env slice delimiter when it's not
+// given, and the key from the value using the kvsep tag option, which defaults
+// to DefaultEnvMapKeyValueDelim. Keys and values can be of any type that has a
+// ParserFunc or implements encoding.TextUnmarshaler.
+func handleMap(
+	parserCtx *ParserCtx,
+	pField *parsedField,
+	rValue reflect.Value,
+) error {
+	delim, ok := pField.tagOpts.getSep()
+	if !ok {
+		delim = parserCtx.GetEnvSliceDelim()
+		if v := pField.GetEnvValue(); gobag.StringIsEmpty(v) {
+			delim = defaultDelim
+		}
+	}
+	kvDelim, ok := pField.tagOpts.getKeyValueSep()
+	if !ok {
+		kvDelim = DefaultEnvMapKeyValueDelim
+	}
+
+	mapType := rValue.Type()
+	result := reflect.MakeMap(mapType)
+	for _, part := range strings.Split(pField.getFieldValue(), delim) {
+		if gobag.StringIsEmpty(part) {
+			continue
+		}
+		kv := strings.SplitN(part, kvDelim, 2)
+		if len(kv) < 2 {
+			return newParseError(
+				pField,
+				part,
+				errorx.New(fmt.Sprintf("map entry %q is missing the key value delimiter %q", part, kvDelim)),
+			)
+		}
+
+		key, err := parseValue(parserCtx, pField, mapType.Key(), strings.TrimSpace(kv[0]))
+		if err != nil {
+			return err
+		}
+		if result.MapIndex(key).IsValid() {
+			return newParseError(
+				pField,
+				strings.TrimSpace(kv[0]),
+				errorx.New(fmt.Sprintf("duplicate map key %q", strings.TrimSpace(kv[0]))),
+			)
+		}
+		value, err := parseValue(parserCtx, pField, mapType.Elem(), strings.TrimSpace(kv[1]))
+		if err != nil {
+			return err
+		}
+		result.SetMapIndex(key, value)
+	}
+
+	rValue.Set(result)
+	return nil
+}
+
+// parseValue converts v into a value of rType using either the
+// encoding.TextUnmarshaler implementation of rType or its ParserFunc.
+func parseValue(
+	parserCtx *ParserCtx,
+	pField *parsedField,
+	rType reflect.Type,
+	v string,
+) (reflect.Value, error) {
+	result, found, err := convertValue(parserCtx, rType, v)
+	if !found {
+		return reflect.Value{}, newNoParserError(pField.GetStructField())
+	}
+	if err != nil {
+		return reflect.Value{}, newParseError(pField, v, err)
+	}
+	return result, nil
+}
+
+// convertValue is parseValue without a field, found is false if rType has no
+// ParserFunc.
+func convertValue(parserCtx ParserCtxGetter, rType reflect.Type, v string) (reflect.Value, bool, error) {
+	if parserFunc := parserCtx.GetParserFuncMap().getPtr(rType); !gobag.IsNil(parserFunc) {
+		val, err := parserFunc(v)
+		if err != nil {
+			return reflect.Value{}, true, err
+		}
+		return parsedValue(val, rType), true, nil
+	}
+
+	elemType := rType
+	if rType.Kind() == reflect.Ptr {
+		elemType = rType.Elem()
+	}
+
+	result := reflect.New(elemType)
+	if unmarshaler, ok := result.Interface().(encoding.TextUnmarshaler); ok {
+		if err := unmarshaler.UnmarshalText([]byte(v)); err != nil {
+			return reflect.Value{}, true, err
+		}
+	} else {
+		parserFunc := parserCtx.GetParserFuncMap().Get(elemType)
+		if gobag.IsNil(parserFunc) {
+			return reflect.Value{}, false, nil
+		}
+		val, err := parserFunc(v)
+		if err != nil {
+			return reflect.Value{}, true, err
+		}
+		result.Elem().Set(reflect.ValueOf(val).Convert(elemType))
+	}
+
+	if rType.Kind() == reflect.Ptr {
+		return result, true, nil
+	}
+	return result.Elem(), true, nil
+}
@@ -0,0 +1,65 @@
+package envar
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse_Map(t *testing.T) {
+	eMap := EnvVarsMap{
+		"LIMITS":    "tenant-a:10, tenant-b:20",
+		"HEADERS":   "X-Request-Source=envar;Accept=application/json",
+		"TIMEOUTS":  "1:1s,2:1m",
+		"POINTERS":  "a:1",
+		"DUPLICATE": "a:1,a:2",
+		"MISSING":   "a:1,b",
+		"INVALID":   "a:one",
+	}
+	loader := SetEnvVarsLoaderFunc(func() EnvVarsMap { return eMap })
+
+	type config struct {
+		Limits   map[string]int          `env:"LIMITS"`
+		Headers  map[string]string       `env:"HEADERS,sep=;,kvsep=="`
+		Timeouts map[uint8]time.Duration `env:"TIMEOUTS"`
+		Pointers map[string]*int         `env:"POINTERS"`
+		Default  map[string]bool         `env:"DEFAULT,default=a:true|b:false"`
+		Unset    map[string]string       `env:"UNSET"`
+		Units    map[string]unmarshaler  `env:"UNITS,default=a:1s"`
+	}
+
+	cfg := config{}
+	_, err := Parse(&cfg, loader)
+	require.NoError(t, err)
+	require.Equal(t, map[string]int{"tenant-a": 10, "tenant-b": 20}, cfg.Limits)
+	require.Equal(t, map[string]string{
+		"X-Request-Source": "envar",
+		"Accept":           "application/json",
+	}, cfg.Headers)
+	require.Equal(t, map[uint8]time.Duration{1: time.Second, 2: time.Minute}, cfg.Timeouts)
+	require.Equal(t, 1, *cfg.Pointers["a"])
+	require.Equal(t, map[string]bool{"a": true, "b": false}, cfg.Default)
+	require.Nil(t, cfg.Unset)
+	require.Equal(t, map[string]unmarshaler{"a": {time.Second}}, cfg.Units)
+
+	errData := []struct {
+		envKey      string
+		errContains string
+	}{
+		{envKey: "DUPLICATE", errContains: `duplicate map key "a"`},
+		{envKey: "MISSING", errContains: `map entry "b" is missing the key value delimiter ":"`},
+		{envKey: "INVALID", errContains: `invalid syntax`},
+	}
+	for i := range errData {
+		type config struct {
+			Map map[string]int `env:"MAP"`
+		}
+
+		_, err := Parse(&config{}, SetEnvVarsLoaderFunc(func() EnvVarsMap {
+			return EnvVarsMap{"MAP": eMap[errData[i].envKey]}
+		}))
+		require.Error(t, err, errData[i].envKey)
+		require.Contains(t, err.Error(), errData[i].errContains)
+	}
+}
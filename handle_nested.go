@@ -3,12 +3,20 @@ package envar
 import (
 	"fmt"
 	"reflect"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/neumachen/errorx"
+	"github.com/neumachen/gobag"
 )
 
 var ErrNestedNotStruct = func(rValue reflect.Value) error {
-	return errorx.New(fmt.Sprintf("field: %v is not a struct but has nested tag option", rValue.Elem().Type().String()))
+	rType := rValue.Type()
+	if rType.Kind() == reflect.Ptr {
+		rType = rType.Elem()
+	}
+	return errorx.New(fmt.Sprintf("field: %v is not a struct but has nested tag option", rType))
 }
 
 func handleNested(
@@ -25,9 +33,34 @@ func handleNested(
 	if rValue.Kind() != reflect.Struct {
 		return nil, ErrNestedNotStruct(rValue)
 	}
-	return parse(parserCtx, rValue)
+	return parse(parserCtx, rValue, pField.nestedScope())
 }
 
+// IndexGapPolicy defines how gaps between the indexes of the env vars of a
+// slice of nested structs are handled, e.g. SERVERS_0_HOST and SERVERS_2_HOST
+// without SERVERS_1_HOST.
+type IndexGapPolicy int
+
+const (
+	// IndexGapStop sizes the slice by the highest contiguous index starting
+	// at 0, the indexes after a gap are ignored.
+	IndexGapStop IndexGapPolicy = iota
+	// IndexGapError returns an error when there is a gap.
+	IndexGapError
+	// IndexGapFill sizes the slice by the highest index, the elements of the
+	// missing indexes are left as zero values and are not parsed, so defaults
+	// and validators are not applied to them.
+	IndexGapFill
+)
+
+// DefaultNestedSliceMaxLen is the maximum number of elements that are
+// discovered from the env vars for a slice of nested structs.
+const DefaultNestedSliceMaxLen = 1024
+
+// handledNestedSlice parses a slice of nested structs. When the field has an
+// env name, elements are discovered from indexed env vars, e.g. SERVERS_0_HOST
+// and SERVERS_1_HOST for `env:"SERVERS,nested"`. Elements already in the slice
+// are kept and parsed as well, added elements without env vars are not.
 func handledNestedSlice(
 	parserCtx *ParserCtx,
 	pField *parsedField,
@@ -37,17 +70,170 @@ func handledNestedSlice(
 		return errorx.New(fmt.Sprintf("nested field: %v is not a slice", rValues.Type().Elem().Name()))
 	}
 
-	result := reflect.MakeSlice(pField.GetStructField().Type, 0, rValues.Len())
-	for i := 0; i < rValues.Len(); i++ {
-		if rValues.Index(i).Kind() != reflect.Struct {
-			return ErrNestedNotStruct(rValues.Index(i))
+	elemType := rValues.Type().Elem()
+	if elemType.Kind() == reflect.Ptr {
+		elemType = elemType.Elem()
+	}
+	if elemType.Kind() != reflect.Struct {
+		return errorx.New(fmt.Sprintf("field: %v is not a slice of structs but has nested tag option", rValues.Type()))
+	}
+
+	indexed := pField.hasNestedEnvName()
+	length := rValues.Len()
+	var found map[int]struct{}
+	if indexed {
+		n, indexes, err := nestedSliceLen(parserCtx, pField)
+		if err != nil {
+			return err
+		}
+		if n > length {
+			length = n
+		}
+		found = make(map[int]struct{}, len(indexes))
+		for _, idx := range indexes {
+			found[idx] = struct{}{}
+		}
+	}
+
+	if length < 1 {
+		return nil
+	}
+
+	result := reflect.MakeSlice(rValues.Type(), length, length)
+	reflect.Copy(result, rValues)
+	for i := 0; i < length; i++ {
+		// the elements of the gaps filled by IndexGapFill are left as zero
+		// values
+		if _, ok := found[i]; indexed && !ok && i >= rValues.Len() {
+			continue
 		}
-		nestedValue := reflect.New(rValues.Type().Elem())
-		if _, err := parse(parserCtx, nestedValue); err != nil {
-			return errorx.New(err)
+
+		elem := result.Index(i)
+		if elem.Kind() == reflect.Ptr {
+			if elem.IsNil() {
+				elem.Set(reflect.New(elemType))
+			}
+			elem = elem.Elem()
+		}
+
+		scope := pField.nestedScope()
+		if indexed {
+			scope = pField.nestedIndexScope(i)
+		}
+		if _, err := parse(parserCtx, elem, scope); err != nil {
+			return err
 		}
-		result = reflect.Append(result, nestedValue)
 	}
 	rValues.Set(result)
 	return nil
 }
+
+// nestedSliceLen returns the number of elements found in the env vars for the
+// slice of nested structs according to the IndexGapPolicy, and the indexes
+// that have env vars.
+func nestedSliceLen(parserCtx *ParserCtx, pField *parsedField) (int, []int, error) {
+	keyPrefix := pField.nestedEnvPrefix() + pField.envPrefixDelim
+	indexes := findEnvIndexes(parserCtx.GetEnvVarsMap(), keyPrefix, pField.envPrefixDelim)
+	if len(indexes) < 1 {
+		return 0, nil, nil
+	}
+
+	length := 0
+	for length < len(indexes) && indexes[length] == length {
+		length++
+	}
+	if length < len(indexes) {
+		switch parserCtx.GetNestedSliceGapPolicy() {
+		case IndexGapError:
+			return 0, nil, errorx.New(fmt.Sprintf("env: no env vars found for index %d of %s but found for index %d", length, keyPrefix, indexes[length]))
+		case IndexGapFill:
+			length = indexes[len(indexes)-1] + 1
+		}
+	}
+
+	if maxLen := parserCtx.GetNestedSliceMaxLen(); maxLen > 0 && length > maxLen {
+		return 0, nil, errorx.New(fmt.Sprintf("env: %d elements found for %s exceeds the maximum of %d", length, keyPrefix, maxLen))
+	}
+	return length, indexes, nil
+}
+
+// findEnvIndexes returns the sorted indexes found in the keys that start with
+// the keyPrefix and are followed by an index and the delim, e.g. 0 and 1 for
+// SERVERS_0_HOST and SERVERS_1_PORT when the keyPrefix is SERVERS_.
+func findEnvIndexes(eMap EnvVarsMap, keyPrefix, delim string) []int {
+	seen := make(map[int]struct{})
+	for k := range eMap {
+		if !strings.HasPrefix(k, keyPrefix) {
+			continue
+		}
+		rest := k[len(keyPrefix):]
+		end := strings.Index(rest, delim)
+		if end < 1 {
+			continue
+		}
+		idx, err := strconv.Atoi(rest[:end])
+		// indexes with a sign or leading zeros are not indexes
+		if err != nil || strconv.Itoa(idx) != rest[:end] {
+			continue
+		}
+		seen[idx] = struct{}{}
+	}
+
+	indexes := make([]int, 0, len(seen))
+	for idx := range seen {
+		indexes = append(indexes, idx)
+	}
+	sort.Ints(indexes)
+	return indexes
+}
+
+// nestedScope returns the scope inherited by the fields of the nested struct.
+func (p *parsedField) nestedScope() parseScope {
+	return parseScope{
+		fieldPath: p.GetFieldPath(),
+		envPrefix: p.nestedEnvPrefix(),
+	}
+}
+
+// nestedEnvPrefix returns the env prefix of the fields of the nested struct,
+// which is the env key of the nested field, e.g. APP_DB for `env:"DB,nested"`
+// when the prefix is APP. The prefix option replaces the env name, which
+// allows reusing a struct under different names. When neither is given the
+// prefix is inherited as is.
+func (p *parsedField) nestedEnvPrefix() string {
+	name := p.GetEnvName()
+	if v, ok := p.tagOpts.getPrefix(); ok {
+		name = v
+	}
+	if gobag.StringIsEmpty(name) {
+		return p.GetEnvPrefix()
+	}
+	if gobag.StringIsEmpty(p.GetEnvPrefix()) {
+		return name
+	}
+	return strings.Join([]string{p.GetEnvPrefix(), name}, p.envPrefixDelim)
+}
+
+// nestedIndexScope returns the scope inherited by the fields of the element
+// found at index i of a slice of nested structs.
+func (p *parsedField) nestedIndexScope(i int) parseScope {
+	return p.nestedElemScope(strconv.Itoa(i))
+}
+
+// nestedElemScope is like nestedIndexScope but takes the index as a string, so
+// that a placeholder can be used when describing the struct.
+func (p *parsedField) nestedElemScope(idx string) parseScope {
+	return parseScope{
+		fieldPath: p.GetFieldPath() + "[" + idx + "]",
+		envPrefix: strings.Join([]string{p.nestedEnvPrefix(), idx}, p.envPrefixDelim),
+	}
+}
+
+// hasNestedEnvName reports whether the nested field has either an env name or
+// the prefix option.
+func (p *parsedField) hasNestedEnvName() bool {
+	if _, ok := p.tagOpts.getPrefix(); ok {
+		return true
+	}
+	return !gobag.StringIsEmpty(p.GetEnvName())
+}
@@ -0,0 +1,183 @@
+package envar
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type postgres struct {
+	Host string `env:"HOST"`
+	Port int    `env:"PORT,default=5432"`
+}
+
+type database struct {
+	Primary postgres  `env:"PRIMARY,nested"`
+	Replica *postgres `env:"PRIMARY,nested,prefix=REPLICA"`
+	Shared  postgres  `env:",nested"`
+}
+
+func TestParse_NestedPrefix(t *testing.T) {
+	eMap := EnvVarsMap{
+		"APP_DB_PRIMARY_HOST": "primary.internal",
+		"APP_DB_REPLICA_HOST": "replica.internal",
+		"APP_DB_REPLICA_PORT": "6432",
+		"APP_DB_HOST":         "shared.internal",
+	}
+
+	type config struct {
+		DB database `env:"DB,nested"`
+	}
+
+	cfg := config{}
+	_, err := Parse(
+		&cfg,
+		SetEnvPrefix("APP"),
+		SetEnvVarsLoaderFunc(func() EnvVarsMap { return eMap }),
+	)
+	require.NoError(t, err)
+	require.Equal(t, postgres{Host: "primary.internal", Port: 5432}, cfg.DB.Primary)
+	require.Equal(t, &postgres{Host: "replica.internal", Port: 6432}, cfg.DB.Replica)
+	require.Equal(t, postgres{Host: "shared.internal", Port: 5432}, cfg.DB.Shared)
+
+	t.Run("prefix without nested", func(t *testing.T) {
+		type config struct {
+			Host string `env:"HOST,prefix=DB"`
+		}
+
+		_, err := Parse(&config{}, SetEnvVarsLoaderFunc(func() EnvVarsMap { return eMap }))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "prefix requires nested")
+	})
+}
+
+type server struct {
+	Host string `env:"HOST"`
+	Port int    `env:"PORT,default=80"`
+}
+
+func TestParse_NestedSliceIndexed(t *testing.T) {
+	eMap := EnvVarsMap{
+		"SERVERS_0_HOST":  "a.internal",
+		"SERVERS_0_PORT":  "8080",
+		"SERVERS_1_HOST":  "b.internal",
+		"SERVERS_3_HOST":  "d.internal",
+		"SERVERS_01_HOST": "not-an-index",
+		"SERVERS_X_HOST":  "not-an-index",
+		"POOL_0_HOST":     "pool.internal",
+	}
+	loader := SetEnvVarsLoaderFunc(func() EnvVarsMap { return eMap })
+
+	type config struct {
+		Servers []server  `env:"SERVERS,nested"`
+		Pool    []*server `env:"POOL,nested"`
+		Unnamed []server  `env:",nested"`
+		Empty   []server  `env:"EMPTY,nested"`
+	}
+
+	t.Run("stop at gap", func(t *testing.T) {
+		cfg := config{}
+		_, err := Parse(&cfg, loader)
+		require.NoError(t, err)
+		require.Equal(t, []server{
+			{Host: "a.internal", Port: 8080},
+			{Host: "b.internal", Port: 80},
+		}, cfg.Servers)
+		require.Equal(t, []*server{{Host: "pool.internal", Port: 80}}, cfg.Pool)
+		require.Nil(t, cfg.Unnamed)
+		require.Nil(t, cfg.Empty)
+	})
+
+	t.Run("fill gaps", func(t *testing.T) {
+		cfg := config{}
+		_, err := Parse(&cfg, loader, SetNestedSliceGapPolicy(IndexGapFill))
+		require.NoError(t, err)
+		require.Equal(t, []server{
+			{Host: "a.internal", Port: 8080},
+			{Host: "b.internal", Port: 80},
+			{},
+			{Host: "d.internal", Port: 80},
+		}, cfg.Servers)
+	})
+
+	t.Run("fill gaps without parsing them", func(t *testing.T) {
+		type config struct {
+			Servers []struct {
+				Host string `env:"HOST,validate=required"`
+				Port int    `env:"PORT,default=80"`
+			} `env:"SERVERS,nested"`
+		}
+
+		cfg := config{}
+		_, err := Parse(&cfg, SetNestedSliceGapPolicy(IndexGapFill), SetEnvVarsLoaderFunc(EnvVarsMapLoaderFunc(EnvVarsMap{
+			"SERVERS_0_HOST": "a.internal",
+			"SERVERS_2_HOST": "c.internal",
+		})))
+		require.NoError(t, err)
+		require.Len(t, cfg.Servers, 3)
+		require.Equal(t, "a.internal", cfg.Servers[0].Host)
+		require.Zero(t, cfg.Servers[1])
+		require.Equal(t, "c.internal", cfg.Servers[2].Host)
+		require.Equal(t, 80, cfg.Servers[2].Port)
+	})
+
+	t.Run("error on gap", func(t *testing.T) {
+		_, err := Parse(&config{}, loader, SetNestedSliceGapPolicy(IndexGapError))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "index 2 of SERVERS_")
+	})
+
+	t.Run("max len", func(t *testing.T) {
+		_, err := Parse(&config{}, loader, SetNestedSliceMaxLen(1))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "exceeds the maximum of 1")
+	})
+
+	t.Run("existing elements", func(t *testing.T) {
+		cfg := config{
+			Servers: []server{{}, {}, {Host: "c.internal", Port: 9090}},
+			Unnamed: []server{{Host: "kept"}},
+		}
+		_, err := Parse(&cfg, loader)
+		require.NoError(t, err)
+		require.Equal(t, []server{
+			{Host: "a.internal", Port: 8080},
+			{Host: "b.internal", Port: 80},
+			{Host: "c.internal", Port: 80},
+		}, cfg.Servers)
+		require.Equal(t, []server{{Host: "kept", Port: 80}}, cfg.Unnamed)
+	})
+
+	t.Run("field path", func(t *testing.T) {
+		type config struct {
+			Servers []struct {
+				Port int `env:"PORT"`
+			} `env:"SERVERS,nested"`
+		}
+
+		_, err := Parse(&config{}, SetEnvVarsLoaderFunc(func() EnvVarsMap {
+			return EnvVarsMap{"SERVERS_0_PORT": "http"}
+		}))
+		var fieldErr *FieldError
+		require.ErrorAs(t, err, &fieldErr)
+		require.Equal(t, "config.Servers[0].Port", fieldErr.FieldPath)
+		require.Equal(t, "SERVERS_0_PORT", fieldErr.EnvKey)
+	})
+}
+
+func TestParse_NestedNotStruct(t *testing.T) {
+	type config struct {
+		Port int `env:"PORT,nested"`
+	}
+
+	_, err := Parse(&config{}, SetEnvVarsLoaderFunc(EnvVarsMapLoaderFunc(EnvVarsMap{"PORT": "80"})))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "field: int is not a struct but has nested tag option")
+
+	type ptrConfig struct {
+		Port *int `env:"PORT,nested"`
+	}
+	_, err = Parse(&ptrConfig{}, SetEnvVarsLoaderFunc(EnvVarsMapLoaderFunc(EnvVarsMap{"PORT": "80"})))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "field: int is not a struct but has nested tag option")
+}
@@ -19,16 +19,22 @@ func handleSlice(
 	}
 	parts := strings.Split(pField.getFieldValue(), delim)
 
-	fieldElem := pField.GetStructField().Type.Elem()
+	elemType := pField.GetStructField().Type.Elem()
+	fieldElem := elemType
 	if fieldElem.Kind() == reflect.Ptr {
 		fieldElem = fieldElem.Elem()
 	}
 
-	if _, ok := reflect.New(fieldElem).Interface().(encoding.TextUnmarshaler); ok {
-		return parseTextUnmarshalers(rValue, parts, pField.GetStructField())
+	parserFunc := pField.timeParserFunc(parserCtx, fieldElem)
+	if gobag.IsNil(parserFunc) {
+		parserFunc = parserCtx.GetParserFuncMap().getPtr(elemType)
+	}
+	if gobag.IsNil(parserFunc) {
+		if _, ok := reflect.New(fieldElem).Interface().(encoding.TextUnmarshaler); ok {
+			return parseTextUnmarshalers(pField, rValue, parts)
+		}
+		parserFunc = parserCtx.GetParserFuncMap().Get(fieldElem)
 	}
-
-	parserFunc := parserCtx.GetParserFuncMap().Get(fieldElem)
 	if gobag.IsNil(parserFunc) {
 		return newNoParserError(pField.GetStructField())
 	}
@@ -37,15 +43,23 @@ func handleSlice(
 	for _, part := range parts {
 		r, err := parserFunc(part)
 		if err != nil {
-			return newParseError(pField.GetStructField(), err)
+			return newParseError(pField, part, err)
 		}
-		v := reflect.ValueOf(r).Convert(fieldElem)
-		if pField.GetStructField().Type.Elem().Kind() == reflect.Ptr {
-			v = reflect.New(fieldElem)
-			v.Elem().Set(reflect.ValueOf(r).Convert(fieldElem))
-		}
-		result = reflect.Append(result, v)
+		result = reflect.Append(result, parsedValue(r, elemType))
 	}
 	rValue.Set(result)
 	return nil
 }
+
+// isSingleValueSlice reports whether the slice type rType is converted from a
+// single value rather than from delimited elements, e.g. net.IP, because it
+// implements encoding.TextUnmarshaler or has a ParserFunc of its own.
+func isSingleValueSlice(parserCtx ParserCtxGetter, rType reflect.Type) bool {
+	if rType.Kind() != reflect.Slice {
+		return false
+	}
+	if _, ok := reflect.New(rType).Interface().(encoding.TextUnmarshaler); ok {
+		return true
+	}
+	return !gobag.IsNil(parserCtx.GetParserFuncMap().Get(rType))
+}
@@ -0,0 +1,90 @@
+package envar
+
+import (
+	"errors"
+	"reflect"
+)
+
+// BeforeParser is implemented by a struct that needs to run before its fields
+// are parsed, e.g. to set defaults that are computed.
+type BeforeParser interface {
+	BeforeParse()
+}
+
+// AfterParser is implemented by a struct that needs to run once its fields
+// were parsed, e.g. to set a derived field like a DSN. It is not called if one
+// of its fields failed to parse. An error is attributed to the struct and
+// reported like the one of a field.
+type AfterParser interface {
+	AfterParse() error
+}
+
+// StructValidator is implemented by a struct whose fields must be validated
+// together, e.g. a TLS key that requires a TLS cert. Validate is called after
+// AfterParse and the error is added to the ValidationErrorMap with the rule
+// validate. A ValidationError is added as is, its FieldPath is relative to the
+// struct and defaults to the struct. Errors that wrap several errors, e.g.
+// the ones of errors.Join, are added one by one.
+type StructValidator interface {
+	Validate() error
+}
+
+const structValidateRule = "validate"
+
+// hookTarget returns the value on which the hooks of the struct refValue are
+// called, a pointer whenever possible so that they can modify the struct.
+func hookTarget(refValue reflect.Value) interface{} {
+	if refValue.CanAddr() {
+		return refValue.Addr().Interface()
+	}
+	return refValue.Interface()
+}
+
+func callBeforeParse(refValue reflect.Value) {
+	if hook, ok := hookTarget(refValue).(BeforeParser); ok {
+		hook.BeforeParse()
+	}
+}
+
+// callAfterParse calls the AfterParse and Validate hooks of the struct
+// refValue. The error is only returned when parsing must stop.
+func callAfterParse(parserCtx *ParserCtx, refValue reflect.Value, scope parseScope) error {
+	target := hookTarget(refValue)
+	if hook, ok := target.(AfterParser); ok {
+		if err := hook.AfterParse(); err != nil {
+			if err := parserCtx.addFieldError(newFieldError(scope.fieldPath, "", err)); err != nil {
+				return err
+			}
+			return nil
+		}
+	}
+	if hook, ok := target.(StructValidator); ok {
+		if err := hook.Validate(); err != nil {
+			addStructValidationError(parserCtx, scope, err)
+		}
+	}
+	return nil
+}
+
+func addStructValidationError(parserCtx *ParserCtx, scope parseScope, err error) {
+	if multiErr, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, err := range multiErr.Unwrap() {
+			addStructValidationError(parserCtx, scope, err)
+		}
+		return
+	}
+
+	var vErr ValidationError
+	if !errors.As(err, &vErr) {
+		vErr = ValidationError{Message: err.Error()}
+	}
+	if vErr.FieldPath == "" {
+		vErr.FieldPath = scope.fieldPath
+	} else {
+		vErr.FieldPath = scope.joinFieldPath(vErr.FieldPath)
+	}
+	if vErr.Rule == "" {
+		vErr.Rule = structValidateRule
+	}
+	parserCtx.AddFieldValidationError(vErr)
+}
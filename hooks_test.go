@@ -0,0 +1,125 @@
+package envar
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type hooksTLS struct {
+	CertFile string `env:"CERT_FILE"`
+	KeyFile  string `env:"KEY_FILE"`
+}
+
+func (t *hooksTLS) Validate() error {
+	if t.KeyFile != "" && t.CertFile == "" {
+		return ValidationError{FieldPath: "KeyFile", Rule: "requires_cert", Message: "the TLS key requires a TLS cert"}
+	}
+	return nil
+}
+
+type hooksDB struct {
+	Host string `env:"HOST"`
+	Port int    `env:"PORT"`
+	Name string `env:"NAME"`
+	DSN  string
+
+	calls []string
+}
+
+func (d *hooksDB) BeforeParse() {
+	d.calls = append(d.calls, "BeforeParse")
+	d.Port = 5432
+}
+
+func (d *hooksDB) AfterParse() error {
+	d.calls = append(d.calls, "AfterParse")
+	if d.Name == "forbidden" {
+		return errors.New("the database name is forbidden")
+	}
+	d.DSN = fmt.Sprintf("postgres://%s:%d/%s", d.Host, d.Port, d.Name)
+	return nil
+}
+
+func (d *hooksDB) Validate() error {
+	d.calls = append(d.calls, "Validate")
+	var errs []error
+	if d.Host == "" {
+		errs = append(errs, ValidationError{FieldPath: "Host", Message: "the host is required"})
+	}
+	if d.Name == "" {
+		errs = append(errs, errors.New("the name is required"))
+	}
+	return errors.Join(errs...)
+}
+
+type hooksConfig struct {
+	DB       hooksDB   `env:"DB,nested"`
+	Replicas []hooksDB `env:"REPLICAS,nested"`
+	TLS      *hooksTLS `env:"TLS,nested"`
+	Mode     string    `env:"MODE"`
+}
+
+func (c *hooksConfig) Validate() error {
+	if c.Mode == "cluster" && len(c.Replicas) == 0 {
+		return errors.New("cluster mode requires replicas")
+	}
+	return nil
+}
+
+func TestParse_Hooks(t *testing.T) {
+	cfg := hooksConfig{}
+	_, err := Parse(&cfg, SetEnvVarsLoaderFunc(EnvVarsMapLoaderFunc(EnvVarsMap{
+		"DB_HOST":         "primary",
+		"DB_NAME":         "app",
+		"REPLICAS_0_HOST": "replica",
+		"REPLICAS_0_PORT": "6432",
+		"REPLICAS_0_NAME": "app",
+		"MODE":            "cluster",
+	})))
+	require.NoError(t, err)
+	require.Equal(t, "postgres://primary:5432/app", cfg.DB.DSN)
+	require.Equal(t, []string{"BeforeParse", "AfterParse", "Validate"}, cfg.DB.calls)
+	require.Len(t, cfg.Replicas, 1)
+	require.Equal(t, "postgres://replica:6432/app", cfg.Replicas[0].DSN)
+}
+
+func TestParse_Hooks_ValidationErrors(t *testing.T) {
+	_, err := Parse(&hooksConfig{}, SetEnvVarsLoaderFunc(EnvVarsMapLoaderFunc(EnvVarsMap{
+		"TLS_KEY_FILE": "key.pem",
+		"MODE":         "cluster",
+	})))
+	validationErrs := &ValidationErrors{}
+	require.ErrorAs(t, err, &validationErrs)
+	require.Equal(t, []ValidationError{
+		{FieldPath: "hooksConfig", Rule: "validate", Message: "cluster mode requires replicas"},
+		{FieldPath: "hooksConfig.DB", Rule: "validate", Message: "the name is required"},
+		{FieldPath: "hooksConfig.DB.Host", Rule: "validate", Message: "the host is required"},
+		{FieldPath: "hooksConfig.TLS.KeyFile", Rule: "requires_cert", Message: "the TLS key requires a TLS cert"},
+	}, validationErrs.Errors.List())
+}
+
+func TestParse_Hooks_AfterParseError(t *testing.T) {
+	cfg := hooksConfig{}
+	_, err := Parse(&cfg, SetEnvVarsLoaderFunc(EnvVarsMapLoaderFunc(EnvVarsMap{
+		"DB_HOST":         "primary",
+		"DB_NAME":         "forbidden",
+		"REPLICAS_0_PORT": "not a port",
+	})))
+	var parseErrs ParseErrors
+	require.ErrorAs(t, err, &parseErrs)
+	require.Len(t, parseErrs, 2)
+
+	fieldErr := &FieldError{}
+	require.ErrorAs(t, parseErrs[0], &fieldErr)
+	require.Equal(t, "hooksConfig.DB", fieldErr.FieldPath)
+	require.EqualError(t, fieldErr.Err, "the database name is forbidden")
+	require.Equal(t, []string{"BeforeParse", "AfterParse"}, cfg.DB.calls)
+
+	// the hooks of a struct whose fields failed are not called
+	require.ErrorAs(t, parseErrs[1], &fieldErr)
+	require.Equal(t, "hooksConfig.Replicas[0].Port", fieldErr.FieldPath)
+	require.Equal(t, []string{"BeforeParse"}, cfg.Replicas[0].calls)
+}
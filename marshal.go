@@ -0,0 +1,335 @@
+package envar
+
+import (
+	"encoding"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/neumachen/errorx"
+)
+
+// Marshal is the inverse of Parse, it returns the EnvVarsMap that Parse would
+// need to populate a struct like the struct, or pointer to struct, v. The same
+// tag names, prefixes, nested structs and delimiters are used. Nil pointers,
+// slices and maps, and fields with the file tag option are left out.
+func Marshal(v interface{}, setterFuncs ...ParserCtxFuncSetter) (EnvVarsMap, error) {
+	return marshalStruct(v, false, setterFuncs...)
+}
+
+// marshalStruct is Marshal, the values of sensitive fields are replaced by
+// RedactedValue when redact is true.
+func marshalStruct(v interface{}, redact bool, setterFuncs ...ParserCtxFuncSetter) (EnvVarsMap, error) {
+	refValue := reflect.ValueOf(v)
+	if refValue.Kind() == reflect.Ptr {
+		refValue = refValue.Elem()
+	}
+	if refValue.Kind() != reflect.Struct {
+		return nil, ErrNotAStructPtr
+	}
+
+	parserCtx, err := newParserCtx(setterFuncs...)
+	if err != nil {
+		return nil, err
+	}
+
+	m := marshaler{parserCtx: parserCtx, eMap: make(EnvVarsMap), redact: redact}
+	if err := m.marshal(refValue, newRootScope(parserCtx, refValue)); err != nil {
+		return nil, err
+	}
+	return m.eMap, nil
+}
+
+// MarshalEnviron is like Marshal but returns the env vars as KEY=VALUE pairs,
+// e.g. to be used as the Env of an exec.Cmd.
+func MarshalEnviron(v interface{}, setterFuncs ...ParserCtxFuncSetter) ([]string, error) {
+	eMap, err := Marshal(v, setterFuncs...)
+	if err != nil {
+		return nil, err
+	}
+	return eMap.Environ(), nil
+}
+
+type marshaler struct {
+	parserCtx *ParserCtx
+	eMap      EnvVarsMap
+	redact    bool
+}
+
+func (m marshaler) marshal(refValue reflect.Value, scope parseScope) error {
+	for i := 0; i < refValue.NumField(); i++ {
+		structField := refValue.Type().Field(i)
+		if !structField.IsExported() {
+			continue
+		}
+
+		parsedField, err := newParsedField(m.parserCtx, structField, scope)
+		if err != nil {
+			return newFieldError(scope.joinFieldPath(structField.Name), "", err)
+		}
+		if parsedField == nil {
+			continue
+		}
+
+		refField := refValue.Field(i)
+		if parsedField.isNested() {
+			if err := m.marshalNested(parsedField, refField); err != nil {
+				return err
+			}
+			continue
+		}
+		// the value of a file field is the contents of the file, Parse would
+		// read it as a path
+		if parsedField.GetEnvName() == "" || parsedField.tagOpts.getFileKey() {
+			continue
+		}
+
+		v, ok, err := formatField(m.parserCtx, parsedField, refField)
+		if err != nil {
+			return newFieldError(parsedField.GetFieldPath(), parsedField.GetEnvKey(), err)
+		}
+		if !ok {
+			continue
+		}
+		// Parse would expand the references found in the value
+		if parsedField.expandEnv() {
+			v = strings.ReplaceAll(v, "$", "$$")
+		}
+		if m.redact && parsedField.isSensitive() && v != "" {
+			v = RedactedValue
+		}
+		m.eMap.Set(parsedField.GetEnvKey(), v)
+	}
+	return nil
+}
+
+func (m marshaler) marshalNested(pField *parsedField, rValue reflect.Value) error {
+	if rValue.Kind() == reflect.Slice {
+		for i := 0; i < rValue.Len(); i++ {
+			elem := reflect.Indirect(rValue.Index(i))
+			if elem.Kind() != reflect.Struct {
+				continue
+			}
+			scope := pField.nestedScope()
+			if pField.hasNestedEnvName() {
+				scope = pField.nestedIndexScope(i)
+			}
+			if err := m.marshal(elem, scope); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	rValue = reflect.Indirect(rValue)
+	if rValue.Kind() != reflect.Struct {
+		return nil
+	}
+	return m.marshal(rValue, pField.nestedScope())
+}
+
+// formatField returns the env value of the field, ok is false when the field
+// has no value, i.e. a nil pointer, slice or map.
+func formatField(parserCtx *ParserCtx, pField *parsedField, rValue reflect.Value) (string, bool, error) {
+	switch rValue.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Map:
+		if rValue.IsNil() {
+			return "", false, nil
+		}
+	}
+
+	// the TextMarshaler of a Secret redacts the value
+	if secret, ok := rValue.Interface().(secretValuer); ok {
+		return formatField(parserCtx, pField, secret.secretValue())
+	}
+	if v, ok := formatTimeLayout(parserCtx, pField, rValue); ok {
+		return v, true, nil
+	}
+	if v, ok, err := formatTextMarshaler(rValue); ok || err != nil {
+		return v, true, err
+	}
+	// e.g. net.HardwareAddr is a slice that is formatted as a single value
+	if v, ok := formatKnownValue(reflect.Indirect(rValue)); ok {
+		return v, true, nil
+	}
+
+	switch rValue.Kind() {
+	case reflect.Slice:
+		parts := make([]string, rValue.Len())
+		for i := range parts {
+			v, err := formatValue(rValue.Index(i))
+			if err != nil {
+				return "", false, err
+			}
+			if err := checkDelim(v, parserCtx.GetEnvSliceDelim()); err != nil {
+				return "", false, err
+			}
+			parts[i] = v
+		}
+		return strings.Join(parts, parserCtx.GetEnvSliceDelim()), true, nil
+	case reflect.Map:
+		delim, ok := pField.tagOpts.getSep()
+		if !ok {
+			delim = parserCtx.GetEnvSliceDelim()
+		}
+		kvDelim, ok := pField.tagOpts.getKeyValueSep()
+		if !ok {
+			kvDelim = DefaultEnvMapKeyValueDelim
+		}
+
+		parts := make([]string, 0, rValue.Len())
+		iter := rValue.MapRange()
+		for iter.Next() {
+			k, err := formatValue(iter.Key())
+			if err != nil {
+				return "", false, err
+			}
+			v, err := formatValue(iter.Value())
+			if err != nil {
+				return "", false, err
+			}
+			// the value is split from the key at the first kvDelim, so only
+			// the key cannot contain it
+			for _, err := range []error{checkDelim(k, delim), checkDelim(k, kvDelim), checkDelim(v, delim)} {
+				if err != nil {
+					return "", false, err
+				}
+			}
+			parts = append(parts, k+kvDelim+v)
+		}
+		sort.Strings(parts)
+		return strings.Join(parts, delim), true, nil
+	}
+
+	v, err := formatValue(rValue)
+	return v, true, err
+}
+
+// checkDelim returns an error if the formatted element v contains delim, as
+// it would be split at the delim when the env value is parsed. The element is
+// left out of the error as the field may be sensitive.
+func checkDelim(v, delim string) error {
+	if strings.Contains(v, delim) {
+		return errorx.New(fmt.Sprintf("env: an element contains the delimiter %q and would not be parsed back", delim))
+	}
+	return nil
+}
+
+// formatTextMarshaler formats rValue using its encoding.TextMarshaler
+// implementation, ok is false if it has none.
+func formatTextMarshaler(rValue reflect.Value) (string, bool, error) {
+	var marshaler encoding.TextMarshaler
+	if m, ok := rValue.Interface().(encoding.TextMarshaler); ok {
+		marshaler = m
+	} else if rValue.CanAddr() {
+		if m, ok := rValue.Addr().Interface().(encoding.TextMarshaler); ok {
+			marshaler = m
+		}
+	}
+	if marshaler == nil {
+		return "", false, nil
+	}
+
+	b, err := marshaler.MarshalText()
+	if err != nil {
+		return "", true, errorx.New(err)
+	}
+	return string(b), true, nil
+}
+
+// formatValue formats a single value the way the default ParserFuncMap
+// expects it.
+func formatValue(rValue reflect.Value) (string, error) {
+	if rValue.Kind() == reflect.Ptr {
+		if rValue.IsNil() {
+			return "", nil
+		}
+		if loc, ok := rValue.Interface().(*time.Location); ok {
+			return loc.String(), nil
+		}
+		rValue = rValue.Elem()
+	}
+	if v, ok, err := formatTextMarshaler(rValue); ok || err != nil {
+		return v, err
+	}
+
+	if v, ok := formatKnownValue(rValue); ok {
+		return v, nil
+	}
+
+	switch rValue.Kind() {
+	case reflect.String:
+		return rValue.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(rValue.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rValue.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(rValue.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(rValue.Float(), 'g', -1, rValue.Type().Bits()), nil
+	}
+	return "", errorx.New(fmt.Sprintf("env: no formatter found for type %s", rValue.Type()))
+}
+
+// formatKnownValue formats the types of the default ParserFuncMap that don't
+// implement encoding.TextMarshaler, ok is false for any other type.
+func formatKnownValue(rValue reflect.Value) (string, bool) {
+	switch v := rValue.Interface().(type) {
+	case time.Duration:
+		return v.String(), true
+	case url.URL:
+		return v.String(), true
+	case os.File:
+		return v.Name(), true
+	case net.IPNet:
+		return v.String(), true
+	case net.HardwareAddr:
+		return v.String(), true
+	case net.TCPAddr:
+		return v.String(), true
+	case net.UDPAddr:
+		return v.String(), true
+	}
+	return "", false
+}
+
+// formatTimeLayout formats a time.Time field, or a slice of time.Time, with
+// the layout of its layout tag option, ok is false for any other field.
+func formatTimeLayout(parserCtx *ParserCtx, pField *parsedField, rValue reflect.Value) (string, bool) {
+	name, ok := pField.tagOpts.getLayout()
+	if !ok {
+		return "", false
+	}
+	layout := timeLayout(name)
+
+	if t, ok := reflect.Indirect(rValue).Interface().(time.Time); ok {
+		return t.Format(layout), true
+	}
+	if rValue.Kind() != reflect.Slice {
+		return "", false
+	}
+	elemType := rValue.Type().Elem()
+	if elemType.Kind() == reflect.Ptr {
+		elemType = elemType.Elem()
+	}
+	if elemType != timeType {
+		return "", false
+	}
+
+	parts := make([]string, 0, rValue.Len())
+	for i := 0; i < rValue.Len(); i++ {
+		elem := reflect.Indirect(rValue.Index(i))
+		if !elem.IsValid() {
+			continue
+		}
+		parts = append(parts, elem.Interface().(time.Time).Format(layout))
+	}
+	return strings.Join(parts, parserCtx.GetEnvSliceDelim()), true
+}
@@ -0,0 +1,134 @@
+package envar
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type marshalWorker struct {
+	Name    string        `env:"NAME"`
+	Timeout time.Duration `env:"TIMEOUT"`
+}
+
+type marshalConfig struct {
+	Host      string            `env:"HOST"`
+	Port      uint16            `env:"PORT"`
+	Ratio     float64           `env:"RATIO"`
+	Debug     *bool             `env:"DEBUG"`
+	Tags      []string          `env:"TAGS"`
+	Limits    map[string]int    `env:"LIMITS,sep=;,kvsep=="`
+	URL       url.URL           `env:"URL"`
+	Unmarshal unmarshalerText   `env:"UNMARSHAL"`
+	Worker    marshalWorker     `env:"WORKER,nested"`
+	Workers   []marshalWorker   `env:"WORKERS,nested"`
+	Optional  *marshalWorker    `env:"OPTIONAL,nested"`
+	Empty     map[string]string `env:"EMPTY"`
+	NotAnEnv  string
+}
+
+// unmarshalerText implements both encoding.TextMarshaler and
+// encoding.TextUnmarshaler.
+type unmarshalerText struct {
+	Value string
+}
+
+func (u unmarshalerText) MarshalText() ([]byte, error) {
+	return []byte("text:" + u.Value), nil
+}
+
+func (u *unmarshalerText) UnmarshalText(data []byte) error {
+	u.Value = string(data[len("text:"):])
+	return nil
+}
+
+func TestMarshal(t *testing.T) {
+	debug := true
+	u, err := url.Parse("https://example.com/path?q=1")
+	require.NoError(t, err)
+
+	cfg := marshalConfig{
+		Host:      "localhost",
+		Port:      8080,
+		Ratio:     0.25,
+		Debug:     &debug,
+		Tags:      []string{"a", "b"},
+		Limits:    map[string]int{"b": 2, "a": 1},
+		URL:       *u,
+		Unmarshal: unmarshalerText{Value: "value"},
+		Worker:    marshalWorker{Name: "main", Timeout: time.Minute},
+		Workers: []marshalWorker{
+			{Name: "first", Timeout: time.Second},
+			{Name: "second"},
+		},
+		NotAnEnv: "ignored",
+	}
+
+	eMap, err := Marshal(&cfg, SetEnvPrefix("APP"))
+	require.NoError(t, err)
+	require.Equal(t, EnvVarsMap{
+		"APP_HOST":              "localhost",
+		"APP_PORT":              "8080",
+		"APP_RATIO":             "0.25",
+		"APP_DEBUG":             "true",
+		"APP_TAGS":              "a,b",
+		"APP_LIMITS":            "a=1;b=2",
+		"APP_URL":               "https://example.com/path?q=1",
+		"APP_UNMARSHAL":         "text:value",
+		"APP_WORKER_NAME":       "main",
+		"APP_WORKER_TIMEOUT":    "1m0s",
+		"APP_WORKERS_0_NAME":    "first",
+		"APP_WORKERS_0_TIMEOUT": "1s",
+		"APP_WORKERS_1_NAME":    "second",
+		"APP_WORKERS_1_TIMEOUT": "0s",
+	}, eMap)
+
+	parsed := marshalConfig{}
+	_, err = Parse(&parsed, SetEnvPrefix("APP"), SetEnvVarsLoaderFunc(EnvVarsMapLoaderFunc(eMap)))
+	require.NoError(t, err)
+	// Parse always allocates nested struct pointers
+	cfg.Optional = &marshalWorker{}
+	cfg.NotAnEnv = ""
+	require.Equal(t, cfg, parsed)
+
+	environ, err := MarshalEnviron(marshalWorker{Name: "w", Timeout: time.Second})
+	require.NoError(t, err)
+	require.Equal(t, []string{"NAME=w", "TIMEOUT=1s"}, environ)
+
+	_, err = Marshal("not a struct")
+	require.ErrorIs(t, err, ErrNotAStructPtr)
+}
+
+func TestMarshal_Delimiters(t *testing.T) {
+	type config struct {
+		Tags   []string          `env:"TAGS"`
+		Labels map[string]string `env:"LABELS"`
+	}
+
+	// a value may contain the key value delimiter of a map
+	cfg := config{
+		Tags:   []string{"a;b", "c"},
+		Labels: map[string]string{"query": "q=1"},
+	}
+	eMap, err := Marshal(cfg)
+	require.NoError(t, err)
+	require.Equal(t, EnvVarsMap{"TAGS": "a;b,c", "LABELS": "query:q=1"}, eMap)
+
+	parsed := config{}
+	_, err = Parse(&parsed, SetEnvVarsLoaderFunc(EnvVarsMapLoaderFunc(eMap)))
+	require.NoError(t, err)
+	require.Equal(t, cfg, parsed)
+
+	for _, cfg := range []config{
+		{Tags: []string{"a,b", "c"}},
+		{Labels: map[string]string{"a,b": "c"}},
+		{Labels: map[string]string{"a:b": "c"}},
+		{Labels: map[string]string{"a": "b,c"}},
+	} {
+		_, err := Marshal(cfg)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "contains the delimiter")
+	}
+}
@@ -0,0 +1,147 @@
+package envar
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+	"strconv"
+
+	"github.com/neumachen/errorx"
+)
+
+// HostPort is a host and a port, e.g. localhost:8080 or :8080, the host can be
+// empty and is not resolved. The port is between 1 and 65535.
+type HostPort struct {
+	Host string
+	Port uint16
+}
+
+// ParseHostPort parses v as a HostPort.
+func ParseHostPort(v string) (HostPort, error) {
+	host, port, err := net.SplitHostPort(v)
+	if err != nil {
+		return HostPort{}, errorx.New(fmt.Sprintf("unable to parse host:port: %v", err.Error()))
+	}
+	p, err := parsePort(port)
+	if err != nil {
+		return HostPort{}, err
+	}
+	if p == 0 {
+		return HostPort{}, errorx.New(fmt.Sprintf("port %s is not between 1 and 65535", port))
+	}
+	return HostPort{Host: host, Port: p}, nil
+}
+
+// String returns the host and the port joined by a colon, an IPv6 host is
+// enclosed in square brackets.
+func (h HostPort) String() string {
+	return net.JoinHostPort(h.Host, strconv.Itoa(int(h.Port)))
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (h HostPort) MarshalText() ([]byte, error) {
+	return []byte(h.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (h *HostPort) UnmarshalText(text []byte) error {
+	v, err := ParseHostPort(string(text))
+	if err != nil {
+		return err
+	}
+	*h = v
+	return nil
+}
+
+func parsePort(v string) (uint16, error) {
+	port, err := strconv.ParseUint(v, 10, 16)
+	if err != nil {
+		return 0, errorx.New(fmt.Sprintf("port %s is not between 0 and 65535", v))
+	}
+	return uint16(port), nil
+}
+
+// parseIPPort parses an IP address and a port, e.g. 127.0.0.1:80, [::1]:80 or
+// :80. The address is not resolved, a host name is an error.
+func parseIPPort(v string) (net.IP, int, string, error) {
+	host, port, err := net.SplitHostPort(v)
+	if err != nil {
+		return nil, 0, "", errorx.New(fmt.Sprintf("unable to parse address: %v", err.Error()))
+	}
+	p, err := parsePort(port)
+	if err != nil {
+		return nil, 0, "", err
+	}
+	if host == "" {
+		return nil, int(p), "", nil
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return nil, 0, "", errorx.New(fmt.Sprintf("unable to parse address: %s is not an IP address", host))
+	}
+	return net.IP(addr.AsSlice()), int(p), addr.Zone(), nil
+}
+
+func parseIP(v string) (interface{}, error) {
+	ip := net.ParseIP(v)
+	if ip == nil {
+		return nil, errorx.New(fmt.Sprintf("unable to parse IP address: %s", v))
+	}
+	return ip, nil
+}
+
+func parseIPNet(v string) (interface{}, error) {
+	_, ipNet, err := net.ParseCIDR(v)
+	if err != nil {
+		return nil, errorx.New(fmt.Sprintf("unable to parse CIDR: %v", err.Error()))
+	}
+	return *ipNet, nil
+}
+
+func parseHardwareAddr(v string) (interface{}, error) {
+	addr, err := net.ParseMAC(v)
+	if err != nil {
+		return nil, errorx.New(fmt.Sprintf("unable to parse MAC address: %v", err.Error()))
+	}
+	return addr, nil
+}
+
+func parseTCPAddr(v string) (interface{}, error) {
+	ip, port, zone, err := parseIPPort(v)
+	if err != nil {
+		return nil, err
+	}
+	return net.TCPAddr{IP: ip, Port: port, Zone: zone}, nil
+}
+
+func parseUDPAddr(v string) (interface{}, error) {
+	ip, port, zone, err := parseIPPort(v)
+	if err != nil {
+		return nil, err
+	}
+	return net.UDPAddr{IP: ip, Port: port, Zone: zone}, nil
+}
+
+func parseAddr(v string) (interface{}, error) {
+	addr, err := netip.ParseAddr(v)
+	if err != nil {
+		return nil, errorx.New(fmt.Sprintf("unable to parse IP address: %v", err.Error()))
+	}
+	return addr, nil
+}
+
+func parsePrefix(v string) (interface{}, error) {
+	prefix, err := netip.ParsePrefix(v)
+	if err != nil {
+		return nil, errorx.New(fmt.Sprintf("unable to parse prefix: %v", err.Error()))
+	}
+	return prefix, nil
+}
+
+func parseAddrPort(v string) (interface{}, error) {
+	addrPort, err := netip.ParseAddrPort(v)
+	if err != nil {
+		return nil, errorx.New(fmt.Sprintf("unable to parse address: %v", err.Error()))
+	}
+	return addrPort, nil
+}
@@ -0,0 +1,144 @@
+package envar
+
+import (
+	"net"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type netConfig struct {
+	IP        net.IP           `env:"IP"`
+	IPs       []net.IP         `env:"IPS"`
+	IPNet     net.IPNet        `env:"IP_NET"`
+	IPNets    []*net.IPNet     `env:"IP_NETS"`
+	MAC       net.HardwareAddr `env:"MAC"`
+	TCPAddr   *net.TCPAddr     `env:"TCP_ADDR"`
+	UDPAddr   net.UDPAddr      `env:"UDP_ADDR"`
+	Addr      netip.Addr       `env:"ADDR"`
+	Prefixes  []netip.Prefix   `env:"PREFIXES"`
+	AddrPort  netip.AddrPort   `env:"ADDR_PORT"`
+	Listen    HostPort         `env:"LISTEN"`
+	Upstreams []HostPort       `env:"UPSTREAMS"`
+}
+
+func TestParse_NetTypes(t *testing.T) {
+	eMap := EnvVarsMap{
+		"IP":        "10.0.0.1",
+		"IPS":       "10.0.0.1,::1",
+		"IP_NET":    "10.1.2.3/8",
+		"IP_NETS":   "10.0.0.0/8,fd00::/8",
+		"MAC":       "00:00:5e:00:53:01",
+		"TCP_ADDR":  "[fe80::1%eth0]:8080",
+		"UDP_ADDR":  ":53",
+		"ADDR":      "2001:db8::1",
+		"PREFIXES":  "10.0.0.0/8,192.168.0.0/16",
+		"ADDR_PORT": "127.0.0.1:9000",
+		"LISTEN":    "localhost:8080",
+		"UPSTREAMS": "a.internal:80,[::1]:443",
+	}
+
+	cfg := netConfig{}
+	_, err := Parse(&cfg, SetEnvVarsLoaderFunc(EnvVarsMapLoaderFunc(eMap)))
+	require.NoError(t, err)
+
+	require.True(t, net.ParseIP("10.0.0.1").Equal(cfg.IP))
+	require.Len(t, cfg.IPs, 2)
+	require.True(t, net.IPv6loopback.Equal(cfg.IPs[1]))
+	require.Equal(t, "10.0.0.0/8", cfg.IPNet.String())
+	require.Len(t, cfg.IPNets, 2)
+	require.Equal(t, "fd00::/8", cfg.IPNets[1].String())
+	require.Equal(t, "00:00:5e:00:53:01", cfg.MAC.String())
+	require.NotNil(t, cfg.TCPAddr)
+	require.Equal(t, 8080, cfg.TCPAddr.Port)
+	require.Equal(t, "eth0", cfg.TCPAddr.Zone)
+	require.Nil(t, cfg.UDPAddr.IP)
+	require.Equal(t, 53, cfg.UDPAddr.Port)
+	require.Equal(t, netip.MustParseAddr("2001:db8::1"), cfg.Addr)
+	require.Equal(t, []netip.Prefix{
+		netip.MustParsePrefix("10.0.0.0/8"),
+		netip.MustParsePrefix("192.168.0.0/16"),
+	}, cfg.Prefixes)
+	require.Equal(t, netip.MustParseAddrPort("127.0.0.1:9000"), cfg.AddrPort)
+	require.Equal(t, HostPort{Host: "localhost", Port: 8080}, cfg.Listen)
+	require.Equal(t, []HostPort{{Host: "a.internal", Port: 80}, {Host: "::1", Port: 443}}, cfg.Upstreams)
+
+	// the values are formatted back the way they are parsed
+	marshaled, err := Marshal(cfg)
+	require.NoError(t, err)
+	require.Equal(t, "10.0.0.0/8", marshaled["IP_NET"])
+	require.Equal(t, "00:00:5e:00:53:01", marshaled["MAC"])
+	require.Equal(t, "[fe80::1%eth0]:8080", marshaled["TCP_ADDR"])
+	require.Equal(t, "a.internal:80,[::1]:443", marshaled["UPSTREAMS"])
+
+	roundTrip := netConfig{}
+	_, err = Parse(&roundTrip, SetEnvVarsLoaderFunc(EnvVarsMapLoaderFunc(marshaled)))
+	require.NoError(t, err)
+	require.Equal(t, cfg, roundTrip)
+}
+
+func TestParse_NetTypesErrors(t *testing.T) {
+	tests := []struct {
+		key   string
+		value string
+		// the invalid element of a slice
+		invalid string
+	}{
+		{key: "IP", value: "10.0.0.256"},
+		{key: "IPS", value: "10.0.0.1,nope", invalid: "nope"},
+		{key: "IP_NET", value: "10.0.0.0"},
+		{key: "MAC", value: "00:00:5e"},
+		// host names are not resolved
+		{key: "TCP_ADDR", value: "localhost:8080"},
+		{key: "UDP_ADDR", value: "10.0.0.1:65536"},
+		{key: "ADDR", value: "10.0.0.1:80"},
+		{key: "ADDR_PORT", value: "10.0.0.1"},
+		{key: "LISTEN", value: "localhost:0"},
+		{key: "LISTEN", value: "localhost:70000"},
+		{key: "UPSTREAMS", value: "[::1]:80,a.internal", invalid: "a.internal"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key+"="+tt.value, func(t *testing.T) {
+			cfg := netConfig{}
+			_, err := Parse(&cfg, SetEnvVarsLoaderFunc(EnvVarsMapLoaderFunc(EnvVarsMap{tt.key: tt.value})))
+			require.Error(t, err)
+
+			parseErr := &ParseError{}
+			require.ErrorAs(t, err, &parseErr)
+			invalid := tt.value
+			if tt.invalid != "" {
+				invalid = tt.invalid
+			}
+			require.Equal(t, invalid, parseErr.Value())
+		})
+	}
+}
+
+func TestParse_NetTypesValidators(t *testing.T) {
+	type config struct {
+		IP  net.IP           `env:"IP,validate=ipv4|oneof=10.0.0.1 10.0.0.2"`
+		IPs []net.IP         `env:"IPS,validate=ipv6"`
+		MAC net.HardwareAddr `env:"MAC,validate=oneof=00:00:5e:00:53:01"`
+	}
+
+	cfg := config{}
+	_, err := Parse(&cfg, SetEnvVarsLoaderFunc(EnvVarsMapLoaderFunc(EnvVarsMap{
+		"IP":  "10.0.0.2",
+		"IPS": "::1,fd00::1",
+		"MAC": "00:00:5e:00:53:01",
+	})))
+	require.NoError(t, err)
+
+	cfg = config{}
+	parserCtx, err := Parse(&cfg, SetEnvVarsLoaderFunc(EnvVarsMapLoaderFunc(EnvVarsMap{
+		"IP":  "10.0.0.3",
+		"IPS": "::1,10.0.0.1",
+	})))
+	require.Error(t, err)
+	errs := parserCtx.GetValidationErrors()
+	require.Len(t, errs["config.IP"], 1)
+	require.Equal(t, "oneof", errs["config.IP"][0].Rule)
+	require.Len(t, errs["config.IPs"], 1)
+	require.Equal(t, "ipv6", errs["config.IPs"][0].Rule)
+}
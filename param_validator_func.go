@@ -0,0 +1,56 @@
+package envar
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/neumachen/errorx"
+	"github.com/neumachen/gobag"
+)
+
+// ParamValidatorFunc is like a ValidatorFunc but it also receives the param of
+// the rule, e.g. ^[a-z]+$ for regex=^[a-z]+$. Parsing the param is up to the
+// validator.
+type ParamValidatorFunc func(parserCtx ParserCtxAccessor, parsedField ParsedFieldGetter, param string) error
+
+type ParamValidatorFuncsMap map[string]ParamValidatorFunc
+
+func (v ParamValidatorFuncsMap) GetLength() int {
+	return len(v)
+}
+
+func (v ParamValidatorFuncsMap) Add(key string, fn ParamValidatorFunc) {
+	v[key] = fn
+}
+
+var defaultParamValidatorsFunc = ParamValidatorFuncsMap{
+	"regex": validateRegex,
+}
+
+// validateRuleDelim separates the name of a validate rule from its param.
+const validateRuleDelim = "="
+
+// splitValidateRule splits the rule into the name of the validator and its
+// param, ok is false if the rule has no param.
+func splitValidateRule(rule string) (name, param string, ok bool) {
+	return strings.Cut(rule, validateRuleDelim)
+}
+
+func validateRegex(parserCtx ParserCtxAccessor, parsedField ParsedFieldGetter, param string) error {
+	v := parsedField.GetFieldValue()
+	if gobag.StringIsEmpty(v) {
+		return nil
+	}
+	re, err := regexp.Compile(param)
+	if err != nil {
+		return errorx.New(fmt.Sprintf("validator func: %s: invalid param %q: %v", parsedField.GetValidateRule(), param, err))
+	}
+	if !re.MatchString(v) {
+		parserCtx.AddFieldValidationError(NewValidationError(
+			parsedField,
+			fmt.Sprintf("env key: %s value does not match %s", parsedField.GetEnvKey(), param),
+		))
+	}
+	return nil
+}
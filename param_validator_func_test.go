@@ -0,0 +1,172 @@
+package envar
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type paramValidateConfig struct {
+	Port     int     `env:"PORT,validate=min=1|max=65535"`
+	Ratio    float64 `env:"RATIO,validate=min=0.5"`
+	Name     string  `env:"NAME,validate=min=3|max=5"`
+	Code     string  `env:"CODE,validate=len=2"`
+	LogLevel string  `env:"LOG_LEVEL,default=info,validate=oneof=debug info warn"`
+	Slug     string  `env:"SLUG,validate=regex=^[a-z0-9-]+$"`
+}
+
+func TestParse_ParamValidators(t *testing.T) {
+	cfg := paramValidateConfig{}
+	_, err := Parse(&cfg, SetEnvVarsLoaderFunc(EnvVarsMapLoaderFunc(EnvVarsMap{
+		"PORT":  "8080",
+		"RATIO": "0.5",
+		"NAME":  "héllo",
+		"CODE":  "nl",
+		"SLUG":  "my-app-2",
+	})))
+	require.NoError(t, err)
+	require.Equal(t, paramValidateConfig{
+		Port:     8080,
+		Ratio:    0.5,
+		Name:     "héllo",
+		Code:     "nl",
+		LogLevel: "info",
+		Slug:     "my-app-2",
+	}, cfg)
+
+	_, err = Parse(&paramValidateConfig{}, SetEnvVarsLoaderFunc(EnvVarsMapLoaderFunc(EnvVarsMap{
+		"PORT":      "70000",
+		"RATIO":     "0.1",
+		"NAME":      "ab",
+		"CODE":      "nld",
+		"LOG_LEVEL": "trace",
+		"SLUG":      "My App",
+	})))
+	validationErrs := &ValidationErrors{}
+	require.ErrorAs(t, err, &validationErrs)
+
+	valErrors := validationErrs.Errors
+	require.Equal(t, []ValidationError{{
+		FieldPath: "paramValidateConfig.Port",
+		EnvKey:    "PORT",
+		Rule:      "max",
+		Args:      []string{"65535"},
+		Message:   "env key: PORT value 70000 is greater than 65535",
+	}}, valErrors.Get("paramValidateConfig.Port"))
+	require.Equal(t, "env key: RATIO value 0.1 is less than 0.5", valErrors.Get("paramValidateConfig.Ratio")[0].Message)
+	require.Equal(t, "env key: NAME value length 2 is less than 3", valErrors.Get("paramValidateConfig.Name")[0].Message)
+	require.Equal(t, "env key: CODE value length 3 is not 2", valErrors.Get("paramValidateConfig.Code")[0].Message)
+	require.Equal(t, []ValidationError{{
+		FieldPath: "paramValidateConfig.LogLevel",
+		EnvKey:    "LOG_LEVEL",
+		Rule:      "oneof",
+		Args:      []string{"debug", "info", "warn"},
+		Message:   "env key: LOG_LEVEL value trace is not one of: debug, info, warn",
+	}}, valErrors.Get("paramValidateConfig.LogLevel"))
+	require.Equal(t, "env key: SLUG value does not match ^[a-z0-9-]+$", valErrors.Get("paramValidateConfig.Slug")[0].Message)
+}
+
+func TestParse_ParamValidators_InvalidParam(t *testing.T) {
+	type minConfig struct {
+		Port int `env:"PORT,validate=min=one"`
+	}
+	type regexConfig struct {
+		Slug string `env:"SLUG,validate=regex=[a-z"`
+	}
+	type unknownConfig struct {
+		Slug string `env:"SLUG,validate=unknown=1"`
+	}
+	loader := SetEnvVarsLoaderFunc(EnvVarsMapLoaderFunc(EnvVarsMap{"PORT": "1", "SLUG": "a"}))
+
+	_, err := Parse(&minConfig{}, loader)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `validator func: min: invalid param "one"`)
+
+	_, err = Parse(&regexConfig{}, loader)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `validator func: regex: invalid param "[a-z"`)
+
+	_, err = Parse(&unknownConfig{}, loader)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "validator func: unknown not found")
+}
+
+func TestParse_AddParamValidatorFunc(t *testing.T) {
+	type config struct {
+		Port int `env:"PORT,validate=port_range=1024-49151"`
+	}
+
+	portRange := func(parserCtx ParserCtxAccessor, parsedField ParsedFieldGetter, param string) error {
+		var lower, upper int
+		if _, err := fmt.Sscanf(param, "%d-%d", &lower, &upper); err != nil {
+			return err
+		}
+		var port int
+		if _, err := fmt.Sscanf(parsedField.GetFieldValue(), "%d", &port); err != nil {
+			return err
+		}
+		if port < lower || port > upper {
+			parserCtx.AddFieldValidationError(NewValidationError(parsedField, "port is out of range "+param))
+		}
+		return nil
+	}
+
+	_, err := Parse(
+		&config{},
+		AddParamValidatorFunc("port_range", portRange),
+		SetEnvVarsLoaderFunc(EnvVarsMapLoaderFunc(EnvVarsMap{"PORT": "80"})),
+	)
+	validationErrs := &ValidationErrors{}
+	require.ErrorAs(t, err, &validationErrs)
+	require.Equal(t, []ValidationError{{
+		FieldPath: "config.Port",
+		EnvKey:    "PORT",
+		Rule:      "port_range",
+		Args:      []string{"1024-49151"},
+		Message:   "port is out of range 1024-49151",
+	}}, validationErrs.Errors.Get("config.Port"))
+
+	// the validator is not added to the defaults
+	_, err = Parse(&config{}, SetEnvVarsLoaderFunc(EnvVarsMapLoaderFunc(EnvVarsMap{"PORT": "80"})))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "validator func: port_range not found")
+}
+
+func TestParse_RegexEscapedDelims(t *testing.T) {
+	type config struct {
+		Mode string `env:"MODE,validate=regex=^(read\\|write)$|not_empty"`
+		Code string `env:"CODE,validate=regex=^[A-Z]{2\\,3}$"`
+	}
+
+	cfg := config{}
+	_, err := Parse(&cfg, SetEnvVarsLoaderFunc(EnvVarsMapLoaderFunc(EnvVarsMap{
+		"MODE": "write",
+		"CODE": "ABC",
+	})))
+	require.NoError(t, err)
+
+	ctx, err := Parse(&config{}, SetEnvVarsLoaderFunc(EnvVarsMapLoaderFunc(EnvVarsMap{
+		"MODE": "append",
+		"CODE": "ABCD",
+	})))
+	require.Error(t, err)
+	valErrors := ctx.GetValidationErrors()
+	require.Equal(t, "env key: MODE value does not match ^(read|write)$", valErrors.Get("config.Mode")[0].Message)
+	require.Equal(t, "env key: CODE value does not match ^[A-Z]{2,3}$", valErrors.Get("config.Code")[0].Message)
+
+	docs, err := Describe(config{})
+	require.NoError(t, err)
+	require.Equal(t, []string{"regex=^(read|write)$", "not_empty"}, docs[0].Validators)
+}
+
+func TestParse_RegexArgs(t *testing.T) {
+	type config struct {
+		Name string `env:"NAME,validate=regex=^[a-z]+ [a-z]+$"`
+	}
+
+	ctx, err := Parse(&config{}, SetEnvVarsLoaderFunc(EnvVarsMapLoaderFunc(EnvVarsMap{"NAME": "john"})))
+	require.Error(t, err)
+	// the param is not a list, it is kept as a single arg
+	require.Equal(t, []string{"^[a-z]+ [a-z]+$"}, ctx.GetValidationErrors().Get("config.Name")[0].Args)
+}
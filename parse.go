@@ -7,55 +7,138 @@ import (
 	"github.com/neumachen/errorx"
 )
 
-func parse(parserCtx *ParserCtx, refValue reflect.Value) (ParserCtxAccessor, error) {
+// parseScope holds the state that the fields of a struct inherit from the
+// struct that contains them.
+type parseScope struct {
+	fieldPath string
+	envPrefix string
+}
+
+func newRootScope(parserCtx *ParserCtx, refValue reflect.Value) parseScope {
+	return parseScope{
+		fieldPath: refValue.Type().Name(),
+		envPrefix: parserCtx.GetEnvPrefix(),
+	}
+}
+
+// joinFieldPath returns the path of the field with the given name, e.g.
+// Config.DB.Host
+func (s parseScope) joinFieldPath(name string) string {
+	if s.fieldPath == "" {
+		return name
+	}
+	return s.fieldPath + "." + name
+}
+
+func parse(parserCtx *ParserCtx, refValue reflect.Value, scope parseScope) (ParserCtxAccessor, error) {
+	callBeforeParse(refValue)
+
+	fieldErrors := len(parserCtx.fieldErrors)
+	fields := make([]*parsedField, 0, refValue.NumField())
 	for i := 0; i < refValue.Type().NumField(); i++ {
 		refField := refValue.Field(i)
 		if !refField.CanSet() {
 			continue
 		}
 
-		parsedField, err := newParsedField(parserCtx, refValue.Type().Field(i))
+		structField := refValue.Type().Field(i)
+		parsedField, err := newParsedField(parserCtx, structField, scope)
 		if err != nil {
-			return nil, errorx.New(err)
-		}
-		if parsedField != nil {
-			if parsedField.isNested() {
-				if refField.Kind() == reflect.Slice {
-					if err := handledNestedSlice(parserCtx, parsedField, refField); err != nil {
-						return nil, errorx.New(err)
-					}
-					continue
-				}
-				if _, err := handleNested(parserCtx, parsedField, refField); err != nil {
-					return nil, errorx.New(err)
-				}
-				continue
+			fieldErr := newFieldError(scope.joinFieldPath(structField.Name), "", err)
+			if err := parserCtx.addFieldError(fieldErr); err != nil {
+				return nil, err
 			}
-			if err := parsedField.setEnvValue(parserCtx.GetEnvVarsMap()); err != nil {
-				return nil, errorx.New(err)
-			}
-			if err := parsedField.validate(parserCtx); err != nil {
-				return nil, errorx.New(err)
-			}
-			if err := parsedField.setField(parserCtx, refField); err != nil {
-				return nil, errorx.New(err)
+			continue
+		}
+		if parsedField == nil {
+			continue
+		}
+		if !parsedField.isNested() {
+			fields = append(fields, parsedField)
+		}
+		if err := parseField(parserCtx, parsedField, refField); err != nil {
+			fieldErr := newFieldError(parsedField.GetFieldPath(), parsedField.GetEnvKey(), err)
+			if err := parserCtx.addFieldError(fieldErr); err != nil {
+				return nil, err
 			}
-			if unset := parsedField.unsetEnv(); unset {
-				os.Unsetenv(parsedField.GetEnvKey())
+		}
+	}
+
+	// the rules that depend on other fields run once they were all parsed
+	siblings := make(ParsedFields, len(fields))
+	for i := range fields {
+		siblings[i] = fields[i]
+	}
+	for i := range fields {
+		if err := fields[i].validateCrossField(parserCtx, siblings); err != nil {
+			fieldErr := newFieldError(fields[i].GetFieldPath(), fields[i].GetEnvKey(), err)
+			if err := parserCtx.addFieldError(fieldErr); err != nil {
+				return nil, err
 			}
-			continue
 		}
 	}
 
+	// the hooks can't rely on the fields when one of them failed
+	if len(parserCtx.fieldErrors) > fieldErrors {
+		return parserCtx, nil
+	}
+	if err := callAfterParse(parserCtx, refValue, scope); err != nil {
+		return nil, err
+	}
 	return parserCtx, nil
 }
 
+func parseField(parserCtx *ParserCtx, parsedField *parsedField, refField reflect.Value) error {
+	if parsedField.isNested() {
+		if refField.Kind() == reflect.Slice {
+			return handledNestedSlice(parserCtx, parsedField, refField)
+		}
+		_, err := handleNested(parserCtx, parsedField, refField)
+		return err
+	}
+	if err := parsedField.setEnvValue(parserCtx.GetEnvVarsMap()); err != nil {
+		return errorx.New(err)
+	}
+	if err := parsedField.expand(parserCtx.GetEnvVarsMap()); err != nil {
+		return errorx.New(err)
+	}
+	if err := parsedField.readFile(parserCtx); err != nil {
+		return err
+	}
+	if err := parsedField.resolve(parserCtx); err != nil {
+		return err
+	}
+	if err := parsedField.validate(parserCtx); err != nil {
+		return errorx.New(err)
+	}
+	if err := parsedField.setField(parserCtx, refField); err != nil {
+		return err
+	}
+	if err := parsedField.validateTyped(parserCtx, refField); err != nil {
+		return errorx.New(err)
+	}
+	if unset := parsedField.unsetEnv(); unset {
+		os.Unsetenv(parsedField.GetEnvKey())
+		if parsedField.fileEnvKey != "" {
+			os.Unsetenv(parsedField.fileEnvKey)
+		}
+	}
+	return nil
+}
+
 // ErrNotAStructPtr is returned if you pass something that is not a pointer to a
 // Struct to Parse.
 var ErrNotAStructPtr = errorx.New("env: expected a pointer to a Struct")
 
 // Parse parses a struct containing `env` tags and loads its values from
 // environment variables.
+//
+// Unless SetFailFast is used, every field of the struct, nested ones
+// included, is parsed and the errors of all the fields that failed are
+// returned together as ParseErrors.
+//
+// When a validator reported a failure a *ValidationErrors error is returned
+// along with the ParserCtxGetter, unless SetValidationAsError(false) is used.
 func Parse(v interface{}, setterFuncs ...ParserCtxFuncSetter) (ParserCtxGetter, error) {
 	ptrRef := reflect.ValueOf(v)
 	if ptrRef.Kind() != reflect.Ptr {
@@ -65,20 +148,57 @@ func Parse(v interface{}, setterFuncs ...ParserCtxFuncSetter) (ParserCtxGetter,
 	if refValue.Kind() != reflect.Struct {
 		return nil, ErrNotAStructPtr
 	}
-	parserCtx := &ParserCtx{}
+	parserCtx, err := newParserCtx(setterFuncs...)
+	if err != nil {
+		return nil, err
+	}
+	parserCtx.envVarsMap = parserCtx.envVarsLoaderFunc()
 
-	for i := range defaultParserCtxSetters {
-		if err := defaultParserCtxSetters[i](parserCtx); err != nil {
-			return nil, errorx.New(err)
+	if _, err := parse(parserCtx, refValue, newRootScope(parserCtx, refValue)); err != nil {
+		return nil, err
+	}
+	var validationErr error
+	if parserCtx.validationAsError && parserCtx.HasValidationErrors() {
+		validationErr = &ValidationErrors{Errors: parserCtx.GetValidationErrors()}
+	}
+	if len(parserCtx.fieldErrors) > 0 {
+		if validationErr != nil {
+			return nil, append(parserCtx.fieldErrors, validationErr)
 		}
+		return nil, parserCtx.fieldErrors
+	}
+	if validationErr != nil {
+		return parserCtx, validationErr
+	}
+	return parserCtx, nil
+}
+
+// ParseAs is the generic version of Parse, it returns a value of type T
+// populated from the environment variables. T is either a struct or a pointer
+// to a struct, which is allocated. The options and the errors are the same as
+// the ones of Parse, the zero value of T is returned along with an error.
+func ParseAs[T any](setterFuncs ...ParserCtxFuncSetter) (T, ParserCtxGetter, error) {
+	var v T
+	var target interface{} = &v
+	if rValue := reflect.ValueOf(&v).Elem(); rValue.Kind() == reflect.Ptr {
+		rValue.Set(reflect.New(rValue.Type().Elem()))
+		target = v
 	}
 
-	for i := range setterFuncs {
-		if err := setterFuncs[i](parserCtx); err != nil {
-			return nil, errorx.New(err)
-		}
+	parserCtx, err := Parse(target, setterFuncs...)
+	if err != nil {
+		var zero T
+		return zero, parserCtx, err
 	}
-	parserCtx.envVarsMap = parserCtx.envVarsLoaderFunc()
+	return v, parserCtx, nil
+}
 
-	return parse(parserCtx, refValue)
+// MustParse is like ParseAs but panics if an error occurred, it is meant to
+// be used in main packages.
+func MustParse[T any](setterFuncs ...ParserCtxFuncSetter) T {
+	v, _, err := ParseAs[T](setterFuncs...)
+	if err != nil {
+		panic(err)
+	}
+	return v
 }
@@ -4,6 +4,8 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -748,8 +750,10 @@ func TestParse_Validate_Required(t *testing.T) {
 	cfg := ConfigValidateRequired{}
 
 	parserCtx, err := Parse(&cfg)
-	require.NoError(t, err)
+	var validationErrs *ValidationErrors
+	require.ErrorAs(t, err, &validationErrs)
 	require.NotNil(t, parserCtx)
+	require.Equal(t, parserCtx.GetValidationErrors(), validationErrs.Errors)
 
 	for i := range testData {
 		testData[i].assertion(t, &cfg)
@@ -758,14 +762,14 @@ func TestParse_Validate_Required(t *testing.T) {
 	valErrors := parserCtx.GetValidationErrors()
 	require.NotEmpty(t, valErrors)
 	fields := []string{
-		"String",
-		"StringPtr",
-		"Strings",
-		"StringPtrs",
-		"File",
-		"FilePtr",
-		"Files",
-		"FilePtrs",
+		"ConfigValidateRequired.String",
+		"ConfigValidateRequired.StringPtr",
+		"ConfigValidateRequired.Strings",
+		"ConfigValidateRequired.StringPtrs",
+		"ConfigValidateRequired.File",
+		"ConfigValidateRequired.FilePtr",
+		"ConfigValidateRequired.Files",
+		"ConfigValidateRequired.FilePtrs",
 	}
 	require.Equal(t, len(fields), valErrors.GetLength())
 
@@ -838,8 +842,10 @@ func TestParse_Validate_NotEmpty(t *testing.T) {
 	cfg := ConfigValidateNotEmpty{}
 
 	parserCtx, err := Parse(&cfg)
-	require.NoError(t, err)
+	var validationErrs *ValidationErrors
+	require.ErrorAs(t, err, &validationErrs)
 	require.NotNil(t, parserCtx)
+	require.Equal(t, parserCtx.GetValidationErrors(), validationErrs.Errors)
 
 	for i := range testData {
 		testData[i].assertion(t, &cfg)
@@ -849,10 +855,10 @@ func TestParse_Validate_NotEmpty(t *testing.T) {
 	require.NotEmpty(t, valErrors)
 
 	fields := []string{
-		"String",
-		"StringPtr",
-		"Strings",
-		"StringPtrs",
+		"ConfigValidateNotEmpty.String",
+		"ConfigValidateNotEmpty.StringPtr",
+		"ConfigValidateNotEmpty.Strings",
+		"ConfigValidateNotEmpty.StringPtrs",
 	}
 	require.Equal(t, len(fields), valErrors.GetLength())
 
@@ -1005,3 +1011,107 @@ func TestParse_File(t *testing.T) {
 		}
 	})
 }
+
+func TestParse_Validate_NotAsError(t *testing.T) {
+	type config struct {
+		String string `env:"STRING,validate=required"`
+	}
+
+	cfg := config{}
+	parserCtx, err := Parse(
+		&cfg,
+		SetEnvVarsLoaderFunc(func() EnvVarsMap { return EnvVarsMap{} }),
+		SetValidationAsError(false),
+	)
+	require.NoError(t, err)
+	require.True(t, parserCtx.HasValidationErrors())
+	require.True(t, parserCtx.GetValidationErrors().HasErrors("config.String"))
+}
+
+func TestParse_AddParserFunc(t *testing.T) {
+	type config struct {
+		Port int `env:"PORT"`
+	}
+
+	named := func(v string) (interface{}, error) {
+		if v == "http" {
+			return 80, nil
+		}
+		return strconv.Atoi(v)
+	}
+	loader := SetEnvVarsLoaderFunc(EnvVarsMapLoaderFunc(EnvVarsMap{"PORT": "http"}))
+
+	cfg := config{}
+	_, err := Parse(&cfg, AddParserFunc(reflect.TypeOf(0), named), loader)
+	require.NoError(t, err)
+	require.Equal(t, 80, cfg.Port)
+
+	// the parser func is not added to the defaults
+	_, err = Parse(&config{}, loader)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `parsing "http": invalid syntax`)
+}
+
+func TestParse_AddValidatorFunc(t *testing.T) {
+	type config struct {
+		String string `env:"STRING,validate=no_spaces"`
+	}
+
+	noSpaces := func(parserCtx ParserCtxAccessor, parsedField ParsedFieldGetter) error {
+		if strings.Contains(parsedField.GetFieldValue(), " ") {
+			parserCtx.AddFieldValidationError(NewValidationError(parsedField, "value contains spaces"))
+		}
+		return nil
+	}
+	loader := SetEnvVarsLoaderFunc(EnvVarsMapLoaderFunc(EnvVarsMap{"STRING": "a b"}))
+
+	parserCtx, err := Parse(&config{}, AddValidatorFunc("no_spaces", noSpaces), loader)
+	require.Error(t, err)
+	require.True(t, parserCtx.GetValidationErrors().HasErrors("config.String"))
+
+	// the validator is not added to the defaults
+	_, err = Parse(&config{}, loader)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "validator func: no_spaces not found")
+}
+
+func TestParseAs(t *testing.T) {
+	type config struct {
+		Host string `env:"HOST,validate=required"`
+		Port int    `env:"PORT,default=8080"`
+	}
+	loader := SetEnvVarsLoaderFunc(EnvVarsMapLoaderFunc(EnvVarsMap{"HOST": "localhost"}))
+
+	cfg, parserCtx, err := ParseAs[config](loader)
+	require.NoError(t, err)
+	require.NotNil(t, parserCtx)
+	require.Equal(t, config{Host: "localhost", Port: 8080}, cfg)
+
+	cfgPtr, _, err := ParseAs[*config](loader)
+	require.NoError(t, err)
+	require.Equal(t, &config{Host: "localhost", Port: 8080}, cfgPtr)
+
+	cfg, parserCtx, err = ParseAs[config](SetEnvVarsLoaderFunc(EnvVarsMapLoaderFunc(EnvVarsMap{})))
+	validationErrs := &ValidationErrors{}
+	require.ErrorAs(t, err, &validationErrs)
+	require.True(t, parserCtx.HasValidationErrors())
+	require.Equal(t, config{}, cfg)
+
+	_, _, err = ParseAs[int]()
+	require.ErrorIs(t, err, ErrNotAStructPtr)
+	_, _, err = ParseAs[**config]()
+	require.ErrorIs(t, err, ErrNotAStructPtr)
+}
+
+func TestMustParse(t *testing.T) {
+	type config struct {
+		Port int `env:"PORT"`
+	}
+
+	cfg := MustParse[config](SetEnvVarsLoaderFunc(EnvVarsMapLoaderFunc(EnvVarsMap{"PORT": "80"})))
+	require.Equal(t, 80, cfg.Port)
+
+	require.Panics(t, func() {
+		MustParse[config](SetEnvVarsLoaderFunc(EnvVarsMapLoaderFunc(EnvVarsMap{"PORT": "http"})))
+	})
+}
@@ -1,6 +1,7 @@
 package envar
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
 	"strings"
@@ -9,12 +10,14 @@ import (
 	"github.com/neumachen/gobag"
 )
 
-func newParsedField(parserCtx *ParserCtx, structField reflect.StructField) (*parsedField, error) {
+func newParsedField(parserCtx *ParserCtx, structField reflect.StructField, scope parseScope) (*parsedField, error) {
 	pField := &parsedField{
 		structField:    structField,
+		fieldPath:      scope.joinFieldPath(structField.Name),
 		tagName:        parserCtx.GetTagName(),
-		envPrefix:      parserCtx.GetEnvPrefix(),
+		envPrefix:      scope.envPrefix,
 		envPrefixDelim: parserCtx.GetEnvPrefixDelim(),
+		redactAll:      parserCtx.GetRedactErrors(),
 	}
 
 	if err := parseStructField(pField); err != nil {
@@ -30,19 +33,36 @@ func newParsedField(parserCtx *ParserCtx, structField reflect.StructField) (*par
 type parsedField struct {
 	tagOpts        tagOpts
 	structField    reflect.StructField
+	fieldPath      string
 	envPrefix      string
 	envPrefixDelim string
 	tagName        string
 	envName        string
 	envValue       string
+	fileEnvKey     string
+	validateRule   string
+	validateParam  string
 	tagFound       bool
 	keyFound       bool
+	sensitive      bool
+	redactAll      bool
 }
 
 func (p *parsedField) GetStructField() reflect.StructField {
 	return p.structField
 }
 
+// GetDescription is the value defined in the struct tag key desc
+func (p *parsedField) GetDescription() string {
+	return p.tagOpts.getDescription()
+}
+
+// GetFieldPath returns the path of the field starting at the struct that was
+// passed to Parse, e.g. Config.DB.Host
+func (p *parsedField) GetFieldPath() string {
+	return p.fieldPath
+}
+
 // GetEnvValue is the value when the env vars was parsed using the GetEnvKey
 func (p *parsedField) GetEnvValue() string {
 	return p.envValue
@@ -93,6 +113,24 @@ func (p *parsedField) GetTagName() string {
 	return p.tagName
 }
 
+// GetValidateRule returns the name of the validator that is currently
+// validating the field.
+func (p *parsedField) GetValidateRule() string {
+	return p.validateRule
+}
+
+// GetValidateParam returns the param of the validate rule that is running,
+// e.g. 1 for min=1.
+func (p *parsedField) GetValidateParam() string {
+	return p.validateParam
+}
+
+// GetFieldValue returns the env value or, when it is empty, the default
+// value.
+func (p *parsedField) GetFieldValue() string {
+	return p.getFieldValue()
+}
+
 func (p *parsedField) isNested() bool {
 	return p.tagOpts.getNested()
 }
@@ -101,17 +139,120 @@ func (p *parsedField) unsetEnv() bool {
 	return p.tagOpts.getUnsetKey()
 }
 
+func (p *parsedField) expandEnv() bool {
+	return p.tagOpts.getExpandKey()
+}
+
+// expand interpolates the variable references found in the env value and the
+// default value using the eMap. It is a no-op unless the expand tag option is
+// set.
+func (p *parsedField) expand(eMap EnvVarsMap) error {
+	if !p.expandEnv() {
+		return nil
+	}
+
+	visiting := make([]string, 0, 1)
+	if p.GetEnvFound() {
+		visiting = append(visiting, p.GetEnvKey())
+	}
+
+	v, err := expandEnvValue(p.GetEnvValue(), eMap, visiting...)
+	if err != nil {
+		return p.expandError(err)
+	}
+	p.envValue = v
+
+	if _, ok := p.tagOpts[tagOptsDefaultKey]; ok {
+		v, err := expandEnvValue(p.GetDefaultValue(), eMap, visiting...)
+		if err != nil {
+			return p.expandError(err)
+		}
+		p.setTagOpts(tagOptsDefaultKey, v)
+	}
+	return nil
+}
+
+// expandError redacts the part of the value quoted by a malformed variable
+// reference error when the errors of the field are redacted.
+func (p *parsedField) expandError(err error) error {
+	syntaxErr := &expandSyntaxError{}
+	if p.redactErrors() && errors.As(err, &syntaxErr) {
+		err = redactedError{err: err, value: syntaxErr.expr}
+	}
+	return errorx.New(err)
+}
+
+// validate runs the validators of the rules that validate the string value.
+// The rules of the TypedValidatorFuncs are left to validateTyped and the ones
+// of the CrossFieldValidatorFuncs to validateCrossField.
 func (p *parsedField) validate(parserCtx *ParserCtx) error {
+	return p.runValidators(parserCtx, func(name, param string, hasParam bool) (bool, error) {
+		if _, ok := parserCtx.typedValidatorFuncsMap[name]; ok {
+			return true, nil
+		}
+		if _, ok := parserCtx.crossFieldValidatorFuncsMap[name]; ok {
+			return true, nil
+		}
+		if vFunc, ok := parserCtx.validatorFuncsMap[name]; ok && !hasParam {
+			return true, vFunc(parserCtx, p)
+		}
+		if pFunc, ok := parserCtx.paramValidatorFuncsMap[name]; ok {
+			return true, pFunc(parserCtx, p, param)
+		}
+		return false, nil
+	})
+}
+
+// validateTyped runs the TypedValidatorFuncs once the fieldValue was set. They
+// are not run if the field has no value.
+func (p *parsedField) validateTyped(parserCtx *ParserCtx, fieldValue reflect.Value) error {
+	if gobag.StringIsEmpty(p.getFieldValue()) {
+		return nil
+	}
+	value := reflect.Indirect(fieldValue)
+	if secret, ok := value.Interface().(secretValuer); ok {
+		value = reflect.Indirect(secret.secretValue())
+	}
+	if !value.IsValid() {
+		return nil
+	}
+
+	return p.runValidators(parserCtx, func(name, param string, _ bool) (bool, error) {
+		if tFunc, ok := parserCtx.typedValidatorFuncsMap[name]; ok {
+			return true, tFunc(parserCtx, p, value, param)
+		}
+		// the other rules are run by validate and validateCrossField
+		return true, nil
+	})
+}
+
+// runValidators calls run with the name and param of every validate rule, run
+// reports whether a validator was found for the rule.
+func (p *parsedField) runValidators(
+	parserCtx *ParserCtx,
+	run func(name, param string, hasParam bool) (bool, error),
+) error {
 	if len(p.tagOpts.getValidate()) < 1 {
 		return nil
 	}
 
-	for i := range p.tagOpts.getValidate() {
-		vFunc, ok := parserCtx.validatorFuncsMap[p.tagOpts.getValidate()[i]]
-		if !ok {
-			return errorx.New(fmt.Sprintf("validator func: %s not found", p.tagOpts.getValidate()[i]))
+	defer func() { p.validateRule, p.validateParam = "", "" }()
+	// the validation errors that are added by the validators are redacted by
+	// the ctx
+	parserCtx.validatingField = p
+	defer func() { parserCtx.validatingField = nil }()
+	for _, rule := range p.tagOpts.getValidate() {
+		name, param, hasParam := splitValidateRule(rule)
+		p.validateRule, p.validateParam = name, param
+
+		found, err := run(name, param, hasParam)
+		if !found {
+			return errorx.New(fmt.Sprintf("validator func: %s not found", name))
 		}
-		if err := vFunc(parserCtx, p); err != nil {
+		if err != nil {
+			if p.redactErrors() {
+				err = redactedError{err: err, value: p.getFieldValue()}
+			}
 			return errorx.New(err)
 		}
 	}
@@ -144,32 +285,69 @@ func (p *parsedField) setField(parserCtx *ParserCtx, fieldValue reflect.Value) e
 		return nil
 	}
 
-	if fieldValue.Kind() == reflect.Slice {
+	if fieldValue.Kind() == reflect.Slice && !isSingleValueSlice(parserCtx, fieldValue.Type()) {
 		return handleSlice(parserCtx, p, fieldValue)
 	}
 
-	if unmarshaler := asTextUnmarshaler(fieldValue); unmarshaler != nil {
-		if err := unmarshaler.UnmarshalText([]byte(p.getFieldValue())); err != nil {
-			return newParseError(p.GetStructField(), err)
+	if fieldValue.Kind() == reflect.Map {
+		return handleMap(parserCtx, p, fieldValue)
+	}
+
+	// the ParserFunc of a pointer type, e.g. *time.Location, returns the
+	// pointer that is set as is
+	if parserFunc := parserCtx.GetParserFuncMap().getPtr(fieldValue.Type()); !gobag.IsNil(parserFunc) {
+		val, err := parserFunc(p.getFieldValue())
+		if err != nil {
+			return newParseError(p, p.getFieldValue(), err)
 		}
+		fieldValue.Set(parsedValue(val, fieldValue.Type()))
 		return nil
 	}
 
-	sType := p.GetStructField().Type
+	// pointers are only set once the value was parsed successfully so that a
+	// field that fails to parse is left untouched
+	target := fieldValue
+	if fieldValue.Kind() == reflect.Ptr {
+		target = reflect.New(fieldValue.Type().Elem()).Elem()
+	}
+	if err := p.setValue(parserCtx, target); err != nil {
+		return err
+	}
+	if fieldValue.Kind() == reflect.Ptr {
+		fieldValue.Set(target.Addr())
+	}
+	return nil
+}
 
-	if p.GetStructField().Type.Kind() == reflect.Ptr {
-		sType = sType.Elem()
-		fieldValue = fieldValue.Elem()
+func (p *parsedField) setValue(parserCtx *ParserCtx, fieldValue reflect.Value) error {
+	if isSecretType(fieldValue.Type()) {
+		return p.setSecret(parserCtx, fieldValue)
+	}
+
+	if parserFunc := p.timeParserFunc(parserCtx, fieldValue.Type()); !gobag.IsNil(parserFunc) {
+		val, err := parserFunc(p.getFieldValue())
+		if err != nil {
+			return newParseError(p, p.getFieldValue(), err)
+		}
+		fieldValue.Set(reflect.ValueOf(val).Convert(fieldValue.Type()))
+		return nil
+	}
+
+	if unmarshaler := asTextUnmarshaler(fieldValue); unmarshaler != nil {
+		if err := unmarshaler.UnmarshalText([]byte(p.getFieldValue())); err != nil {
+			return newParseError(p, p.getFieldValue(), err)
+		}
+		return nil
 	}
 
 	parserFunc := parserCtx.GetParserFuncMap().Get(p.GetStructField().Type)
 	if !gobag.IsNil(parserFunc) {
 		val, err := parserFunc(p.getFieldValue())
 		if err != nil {
-			return newParseError(p.GetStructField(), err)
+			return newParseError(p, p.getFieldValue(), err)
 		}
 
-		fieldValue.Set(reflect.ValueOf(val).Convert(sType))
+		fieldValue.Set(reflect.ValueOf(val).Convert(fieldValue.Type()))
 		return nil
 	}
 
@@ -181,10 +359,16 @@ var _ ParsedFieldGetter = (*parsedField)(nil)
 type ParsedFieldGetter interface {
 	isNested() bool
 	unsetEnv() bool
+	redactErrors() bool
 	GetStructField() reflect.StructField
+	GetFieldPath() string
 	GetEnvValue() string
 	GetEnvFound() bool
 	GetEnvKey() string
+	GetEnvName() string
+	GetValidateRule() string
+	GetValidateParam() string
+	GetFieldValue() string
 }
 
 const DefaultTagName = "env"
@@ -194,11 +378,17 @@ const DefaultEnvSliceDelim = ","
 const tagOptsDelim = ","
 const tagOptsNested = "nested"
 const tagOptsValidateKey = "validate"
-
-// TODO: implement this later
-// const tagOptsExpandKey = "expand"
+const tagOptsExpandKey = "expand"
 const tagOptsUnsetKey = "unset"
 const tagOptsDefaultKey = "default"
+const tagOptsPrefixKey = "prefix"
+const tagOptsSepKey = "sep"
+const tagOptsKeyValueSepKey = "kvsep"
+const tagOptsDescKey = "desc"
+const tagOptsFileKey = "file"
+const tagOptsSensitiveKey = "sensitive"
+const tagOptsResolveKey = "resolve"
+const tagOptsLayoutKey = "layout"
 
 const validateDelim = "|"
 const defaultDelim = "|"
@@ -222,12 +412,14 @@ func (t tagOpts) getNested() bool {
 	return ok
 }
 
+// getValidate returns the rules of the validate option, a | that is escaped,
+// e.g. in regex=^(a\\|b)$, is part of the rule.
 func (t tagOpts) getValidate() []string {
 	v, ok := t[tagOptsValidateKey]
 	if !ok {
 		return nil
 	}
-	return strings.Split(v, validateDelim)
+	return splitEscaped(v, validateDelim)
 }
 
 func (t tagOpts) getUnsetKey() bool {
@@ -238,13 +430,60 @@ func (t tagOpts) getUnsetKey() bool {
 	return gobag.ArrayContainsStr(trueStrs, v)
 }
 
-// func (t tagOpts) getExpandKey() bool {
-// 	v, ok := t[tagOptsExpandKey]
-// 	if !ok {
-// 		return false
-// 	}
-// 	return gobag.ArrayContainsStr(trueStrs, v)
-// }
+func (t tagOpts) getExpandKey() bool {
+	v, ok := t[tagOptsExpandKey]
+	if !ok {
+		return false
+	}
+	return gobag.ArrayContainsStr(trueStrs, v)
+}
+
+// getPrefix returns the value of the prefix option and whether it was set.
+func (t tagOpts) getPrefix() (string, bool) {
+	v, ok := t[tagOptsPrefixKey]
+	return v, ok
+}
+
+// getSep returns the value of the sep option and whether it was set.
+func (t tagOpts) getSep() (string, bool) {
+	v, ok := t[tagOptsSepKey]
+	return v, ok && v != ""
+}
+
+// getKeyValueSep returns the value of the kvsep option and whether it was
+// set.
+func (t tagOpts) getKeyValueSep() (string, bool) {
+	v, ok := t[tagOptsKeyValueSepKey]
+	return v, ok && v != ""
+}
+
+func (t tagOpts) getFileKey() bool {
+	v, ok := t[tagOptsFileKey]
+	if !ok {
+		return false
+	}
+	return gobag.ArrayContainsStr(trueStrs, v)
+}
+
+func (t tagOpts) getResolveKey() bool {
+	_, ok := t[tagOptsResolveKey]
+	return ok
+}
+
+func (t tagOpts) getSensitive() bool {
+	_, ok := t[tagOptsSensitiveKey]
+	return ok
+}
+
+// getLayout returns the value of the layout option and whether it was set.
+func (t tagOpts) getLayout() (string, bool) {
+	v, ok := t[tagOptsLayoutKey]
+	return v, ok && v != ""
+}
+
+func (t tagOpts) getDescription() string {
+	return t[tagOptsDescKey]
+}
 
 func (t tagOpts) getDefaultValue() string {
 	v, ok := t[tagOptsDefaultKey]
@@ -285,12 +524,36 @@ func (t tagValues) isNested() bool {
 // lreturn tagValues{"FOO"}. If the tag is present but no env name is given,
 // e.g, `env:",nested" the tagValues is not removed of empty strings. The
 // reason behind this is that there are times when we want to process a field
-// that is a struct.
+// that is a struct. A comma that is escaped, e.g. `desc=host\\, no port`, is
+// part of the value.
 func getTagValues(p *parsedField) tagValues {
-	tagVals := strings.Split(p.GetStructField().Tag.Get(p.GetTagName()), tagOptsDelim)
+	tagVals := splitEscaped(p.GetStructField().Tag.Get(p.GetTagName()), tagOptsDelim)
 	return tagValues(tagVals)
 }
 
+// splitEscaped splits s around each sep that is not escaped by a backslash,
+// the escaped ones are unescaped, e.g. `a\,b,c` is split in "a,b" and "c".
+func splitEscaped(s, sep string) []string {
+	escaped := `\` + sep
+	parts := []string{}
+	b := strings.Builder{}
+	for len(s) > 0 {
+		switch {
+		case strings.HasPrefix(s, escaped):
+			b.WriteString(sep)
+			s = s[len(escaped):]
+		case strings.HasPrefix(s, sep):
+			parts = append(parts, b.String())
+			b.Reset()
+			s = s[len(sep):]
+		default:
+			b.WriteByte(s[0])
+			s = s[1:]
+		}
+	}
+	return append(parts, b.String())
+}
+
 func parseStructField(p *parsedField) error {
 	tagVals := getTagValues(p)
 	if p.tagFound = tagVals.getLength() > 0; !p.tagFound {
@@ -304,23 +567,47 @@ func parseStructField(p *parsedField) error {
 	}
 
 	for i := range tagVals[1:] {
-		tagOptsKeyVals := strings.Split(tagVals[1:][i], "=")
+		tagOptsKeyVals := strings.SplitN(tagVals[1:][i], "=", 2)
+		tagOptsValue := ""
+		if len(tagOptsKeyVals) > 1 {
+			tagOptsValue = tagOptsKeyVals[1]
+		}
 
 		switch tagOptsKeyVals[0] {
 		case tagOptsNested:
 			p.setTagOpts(tagOptsNested, "true")
-		// case tagOptsExpandKey:
-		// 	p.setTagOpts(tagOptsExpandKey, "true")
+		case tagOptsExpandKey:
+			p.setTagOpts(tagOptsExpandKey, "true")
 		case tagOptsUnsetKey:
 			p.setTagOpts(tagOptsUnsetKey, "true")
 		case tagOptsValidateKey:
-			p.setTagOpts(tagOptsValidateKey, tagOptsKeyVals[1])
+			p.setTagOpts(tagOptsValidateKey, tagOptsValue)
 		case tagOptsDefaultKey:
-			p.setTagOpts(tagOptsDefaultKey, tagOptsKeyVals[1])
+			p.setTagOpts(tagOptsDefaultKey, tagOptsValue)
+		case tagOptsPrefixKey:
+			p.setTagOpts(tagOptsPrefixKey, tagOptsValue)
+		case tagOptsSepKey:
+			p.setTagOpts(tagOptsSepKey, tagOptsValue)
+		case tagOptsKeyValueSepKey:
+			p.setTagOpts(tagOptsKeyValueSepKey, tagOptsValue)
+		case tagOptsDescKey:
+			p.setTagOpts(tagOptsDescKey, tagOptsValue)
+		case tagOptsFileKey:
+			p.setTagOpts(tagOptsFileKey, "true")
+		case tagOptsSensitiveKey:
+			p.setTagOpts(tagOptsSensitiveKey, "true")
+		case tagOptsResolveKey:
+			p.setTagOpts(tagOptsResolveKey, "true")
+		case tagOptsLayoutKey:
+			p.setTagOpts(tagOptsLayoutKey, tagOptsValue)
 		default:
 			return errorx.New(fmt.Sprintf("unrecognized field option key: %s", tagOptsKeyVals[0]))
 		}
 	}
 
+	if _, ok := p.tagOpts.getPrefix(); ok && !p.isNested() {
+		return errorx.New(fmt.Sprintf("field option key: %s requires %s", tagOptsPrefixKey, tagOptsNested))
+	}
+
 	return nil
 }
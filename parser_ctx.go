@@ -3,19 +3,53 @@ package envar
 import (
 	"reflect"
 
+	"github.com/neumachen/errorx"
 	"github.com/neumachen/gobag"
 )
 
+// newParserCtx returns a ParserCtx with the defaults applied followed by the
+// setterFuncs. The env vars are not loaded yet.
+func newParserCtx(setterFuncs ...ParserCtxFuncSetter) (*ParserCtx, error) {
+	parserCtx := &ParserCtx{}
+
+	for i := range defaultParserCtxSetters {
+		if err := defaultParserCtxSetters[i](parserCtx); err != nil {
+			return nil, errorx.New(err)
+		}
+	}
+
+	for i := range setterFuncs {
+		if err := setterFuncs[i](parserCtx); err != nil {
+			return nil, errorx.New(err)
+		}
+	}
+	return parserCtx, nil
+}
+
 type ParserCtx struct {
-	tagName            string
-	envVarsLoaderFunc  EnvVarsLoaderFunc
-	envPrefix          string
-	envPrefixDelim     string
-	envSliceDelim      string
-	parserFuncMap      ParserFuncMap
-	validatorFuncsMap  ValidatorFuncsMap
-	envVarsMap         EnvVarsMap
-	validationErrorMap validationErrorMap
+	tagName                     string
+	envVarsLoaderFunc           EnvVarsLoaderFunc
+	envPrefix                   string
+	envPrefixDelim              string
+	envSliceDelim               string
+	parserFuncMap               ParserFuncMap
+	validatorFuncsMap           ValidatorFuncsMap
+	paramValidatorFuncsMap      ParamValidatorFuncsMap
+	typedValidatorFuncsMap      TypedValidatorFuncsMap
+	crossFieldValidatorFuncsMap CrossFieldValidatorFuncsMap
+	resolverMap                 ResolverMap
+	resolveReferences           bool
+	envVarsMap                  EnvVarsMap
+	validationErrorMap          ValidationErrorMap
+	failFast                    bool
+	nestedSliceMaxLen           int
+	fileEnvSuffix               string
+	fileMaxSize                 int64
+	redactErrors                bool
+	validatingField             *parsedField
+	nestedSliceGaps             IndexGapPolicy
+	validationAsError           bool
+	fieldErrors                 ParseErrors
 }
 
 func (p *ParserCtx) GetTagName() string {
@@ -34,15 +68,43 @@ func (p *ParserCtx) GetEnvSliceDelim() string {
 	return p.envSliceDelim
 }
 
+func (p *ParserCtx) GetRedactErrors() bool {
+	return p.redactErrors
+}
+
+func (p *ParserCtx) GetFileEnvSuffix() string {
+	return p.fileEnvSuffix
+}
+
+func (p *ParserCtx) GetFileMaxSize() int64 {
+	return p.fileMaxSize
+}
+
+func (p *ParserCtx) GetNestedSliceMaxLen() int {
+	return p.nestedSliceMaxLen
+}
+
+func (p *ParserCtx) GetNestedSliceGapPolicy() IndexGapPolicy {
+	return p.nestedSliceGaps
+}
+
 func (p *ParserCtx) GetParserFuncMap() ParserFuncMap {
 	return p.parserFuncMap
 }
 
+func (p *ParserCtx) GetResolverMap() ResolverMap {
+	return p.resolverMap
+}
+
+func (p *ParserCtx) GetResolveReferences() bool {
+	return p.resolveReferences
+}
+
 func (p *ParserCtx) GetEnvVarsMap() EnvVarsMap {
 	return p.envVarsMap
 }
 
-func (p *ParserCtx) GetValidationErrors() validationErrorMap {
+func (p *ParserCtx) GetValidationErrors() ValidationErrorMap {
 	return p.validationErrorMap
 }
 
@@ -75,6 +137,41 @@ func (p *ParserCtx) SetEnvSliceDelim(delim string) error {
 	return nil
 }
 
+func (p *ParserCtx) SetRedactErrors(redact bool) error {
+	p.redactErrors = redact
+	return nil
+}
+
+func (p *ParserCtx) SetFileEnvSuffix(suffix string) error {
+	p.fileEnvSuffix = suffix
+	return nil
+}
+
+func (p *ParserCtx) SetFileMaxSize(maxSize int64) error {
+	p.fileMaxSize = maxSize
+	return nil
+}
+
+func (p *ParserCtx) SetNestedSliceMaxLen(maxLen int) error {
+	p.nestedSliceMaxLen = maxLen
+	return nil
+}
+
+func (p *ParserCtx) SetNestedSliceGapPolicy(policy IndexGapPolicy) error {
+	p.nestedSliceGaps = policy
+	return nil
+}
+
+func (p *ParserCtx) SetFailFast(failFast bool) error {
+	p.failFast = failFast
+	return nil
+}
+
+func (p *ParserCtx) SetValidationAsError(asError bool) error {
+	p.validationAsError = asError
+	return nil
+}
+
 func (p *ParserCtx) SetParserFuncMap(fnMap ParserFuncMap) error {
 	if fnMap.GetLength() < 1 {
 		return nil
@@ -111,11 +208,110 @@ func (p *ParserCtx) AddValidatorFunc(validatorKey string, fn ValidatorFunc) {
 	p.validatorFuncsMap.Add(validatorKey, fn)
 }
 
+func (p *ParserCtx) SetParamValidatorFuncsMap(fnMap ParamValidatorFuncsMap) error {
+	if fnMap.GetLength() < 1 {
+		return nil
+	}
+	p.paramValidatorFuncsMap = fnMap
+	return nil
+}
+
+func (p *ParserCtx) AddParamValidatorFunc(validatorKey string, fn ParamValidatorFunc) {
+	if gobag.IsNil(fn) {
+		return
+	}
+	if p.paramValidatorFuncsMap == nil {
+		p.paramValidatorFuncsMap = make(ParamValidatorFuncsMap)
+	}
+	p.paramValidatorFuncsMap.Add(validatorKey, fn)
+}
+
+func (p *ParserCtx) SetTypedValidatorFuncsMap(fnMap TypedValidatorFuncsMap) error {
+	if fnMap.GetLength() < 1 {
+		return nil
+	}
+	p.typedValidatorFuncsMap = fnMap
+	return nil
+}
+
+func (p *ParserCtx) AddTypedValidatorFunc(validatorKey string, fn TypedValidatorFunc) {
+	if gobag.IsNil(fn) {
+		return
+	}
+	if p.typedValidatorFuncsMap == nil {
+		p.typedValidatorFuncsMap = make(TypedValidatorFuncsMap)
+	}
+	p.typedValidatorFuncsMap.Add(validatorKey, fn)
+}
+
+func (p *ParserCtx) SetCrossFieldValidatorFuncsMap(fnMap CrossFieldValidatorFuncsMap) error {
+	if fnMap.GetLength() < 1 {
+		return nil
+	}
+	p.crossFieldValidatorFuncsMap = fnMap
+	return nil
+}
+
+func (p *ParserCtx) AddCrossFieldValidatorFunc(validatorKey string, fn CrossFieldValidatorFunc) {
+	if gobag.IsNil(fn) {
+		return
+	}
+	if p.crossFieldValidatorFuncsMap == nil {
+		p.crossFieldValidatorFuncsMap = make(CrossFieldValidatorFuncsMap)
+	}
+	p.crossFieldValidatorFuncsMap.Add(validatorKey, fn)
+}
+
+func (p *ParserCtx) SetResolverMap(resolverMap ResolverMap) error {
+	if resolverMap.GetLength() < 1 {
+		return nil
+	}
+	p.resolverMap = resolverMap
+	return nil
+}
+
+func (p *ParserCtx) AddResolver(scheme string, resolver Resolver) {
+	if gobag.IsNil(resolver) {
+		return
+	}
+	if p.resolverMap == nil {
+		p.resolverMap = make(ResolverMap)
+	}
+	p.resolverMap.Add(scheme, resolver)
+}
+
+func (p *ParserCtx) SetResolveReferences(resolve bool) error {
+	p.resolveReferences = resolve
+	return nil
+}
+
 func (p *ParserCtx) AddValidationError(key, value string) {
+	p.AddFieldValidationError(ValidationError{
+		FieldPath: key,
+		Message:   value,
+	})
+}
+
+// AddFieldValidationError adds the validation error, the value of the field
+// that is being validated is redacted from the message when it is sensitive.
+func (p *ParserCtx) AddFieldValidationError(vErr ValidationError) {
+	if p.validatingField != nil {
+		vErr.Message = p.validatingField.redactValue(vErr.Message)
+	}
 	if p.validationErrorMap == nil {
-		p.validationErrorMap = make(validationErrorMap)
+		p.validationErrorMap = make(ValidationErrorMap)
 	}
-	p.validationErrorMap.add(key, value)
+	p.validationErrorMap.add(vErr)
+}
+
+// addFieldError records the error of a field. When fail fast is enabled the
+// error is returned instead so that parsing stops.
+func (p *ParserCtx) addFieldError(err error) error {
+	if p.failFast {
+		return err
+	}
+	p.fieldErrors = append(p.fieldErrors, err)
+	return nil
 }
 
 var _ ParserCtxAccessor = (*ParserCtx)(nil)
@@ -133,16 +329,36 @@ type ParserCtxGetter interface {
 	// GetEnvSliceDelim returns the delimiter used to split the values of
 	// a collection for a given ENV var
 	GetEnvSliceDelim() string
+	// GetRedactErrors returns whether the values of every field are redacted
+	// from the error messages
+	GetRedactErrors() bool
+	// GetFileEnvSuffix returns the suffix of the env key that holds the path
+	// of a file containing the value, empty if disabled
+	GetFileEnvSuffix() string
+	// GetFileMaxSize returns the maximum size in bytes of a file that is read
+	// for the value of a field
+	GetFileMaxSize() int64
+	// GetNestedSliceMaxLen returns the maximum number of elements that are
+	// discovered from the env vars for a slice of nested structs
+	GetNestedSliceMaxLen() int
+	// GetNestedSliceGapPolicy returns how gaps between the indexes of the
+	// env vars of a slice of nested structs are handled
+	GetNestedSliceGapPolicy() IndexGapPolicy
 	// GetParserFuncMap returns ParserFuncMap that will be used to parse
 	// the different types for a given struct field
 	GetParserFuncMap() ParserFuncMap
 	// GetEnvVarsMaps returns the environemnt varialbes that was mapped to
 	// EnvVarsMap
 	GetEnvVarsMap() EnvVarsMap
+	// GetResolverMap returns the Resolvers keyed by scheme
+	GetResolverMap() ResolverMap
+	// GetResolveReferences returns whether the references of every field are
+	// resolved
+	GetResolveReferences() bool
 	// GetValidationErrors returns the validation errors that were added
 	// when validation failed for a given struct field.
-	GetValidationErrors() validationErrorMap
-	// HasValidationErrors returns a boolean if the validationErrorMap has
+	GetValidationErrors() ValidationErrorMap
+	// HasValidationErrors returns a boolean if the ValidationErrorMap has
 	// a length of greater than 0
 	HasValidationErrors() bool
 }
@@ -161,6 +377,28 @@ type ParserCtxSetter interface {
 	// SetEnvSliceDelima sets the delimiter for the ENV var that contains
 	// a collection
 	SetEnvSliceDelim(delim string) error
+	// SetRedactErrors sets whether the values of every field, not only the
+	// sensitive ones, are redacted from the error messages.
+	SetRedactErrors(redact bool) error
+	// SetFileEnvSuffix sets the suffix of the env key that holds the path of
+	// a file containing the value, an empty suffix disables it.
+	SetFileEnvSuffix(suffix string) error
+	// SetFileMaxSize sets the maximum size in bytes of a file that is read for
+	// the value of a field, 0 means there is no maximum.
+	SetFileMaxSize(maxSize int64) error
+	// SetNestedSliceMaxLen sets the maximum number of elements that are
+	// discovered from the env vars for a slice of nested structs, 0 means
+	// there is no maximum.
+	SetNestedSliceMaxLen(maxLen int) error
+	// SetNestedSliceGapPolicy sets how gaps between the indexes of the env
+	// vars of a slice of nested structs are handled.
+	SetNestedSliceGapPolicy(policy IndexGapPolicy) error
+	// SetFailFast sets whether parsing stops at the first field that fails
+	// instead of collecting the errors of every field.
+	SetFailFast(failFast bool) error
+	// SetValidationAsError sets whether Parse returns an error when a
+	// validator reported a failure.
+	SetValidationAsError(asError bool) error
 	// SetParserFuncMap sets the ParserFuncMap using the fnMap. This overrides the
 	// ParserFuncMap for the given ParserCtx. This should only if it's not desired
 	// to use the parsers already defined in this package.
@@ -177,9 +415,44 @@ type ParserCtxSetter interface {
 	// ValidatorFuncsMap. This does not reset the ValidatorFuncsMap but will
 	// override any existing validator that matches the validatorKey.
 	AddValidatorFunc(validatorKey string, fn ValidatorFunc)
+	// SetParamValidatorFuncsMap sets the ParamValidatorFuncsMap, which
+	// overrides the built-in validators that take a param.
+	SetParamValidatorFuncsMap(fnMap ParamValidatorFuncsMap) error
+	// AddParamValidatorFunc adds a validator that takes a param. This does not
+	// reset the ParamValidatorFuncsMap but will override any existing
+	// validator that matches the validatorKey.
+	AddParamValidatorFunc(validatorKey string, fn ParamValidatorFunc)
+	// SetTypedValidatorFuncsMap sets the TypedValidatorFuncsMap, which
+	// overrides the built-in validators that run once the field was set.
+	SetTypedValidatorFuncsMap(fnMap TypedValidatorFuncsMap) error
+	// AddTypedValidatorFunc adds a validator that runs once the field was set.
+	// This does not reset the TypedValidatorFuncsMap but will override any
+	// existing validator that matches the validatorKey.
+	AddTypedValidatorFunc(validatorKey string, fn TypedValidatorFunc)
+	// SetCrossFieldValidatorFuncsMap sets the CrossFieldValidatorFuncsMap,
+	// which overrides the built-in validators that depend on other fields.
+	SetCrossFieldValidatorFuncsMap(fnMap CrossFieldValidatorFuncsMap) error
+	// AddCrossFieldValidatorFunc adds a validator that depends on other
+	// fields. This does not reset the CrossFieldValidatorFuncsMap but will
+	// override any existing validator that matches the validatorKey.
+	AddCrossFieldValidatorFunc(validatorKey string, fn CrossFieldValidatorFunc)
+	// SetResolverMap sets the ResolverMap, which overrides the built-in
+	// Resolvers.
+	SetResolverMap(resolverMap ResolverMap) error
+	// AddResolver adds the Resolver of the scheme. This does not reset the
+	// ResolverMap but will override a Resolver that's already defined for the
+	// scheme.
+	AddResolver(scheme string, resolver Resolver)
+	// SetResolveReferences sets whether the references of every field, not
+	// only the ones with the resolve tag option, are resolved.
+	SetResolveReferences(resolve bool) error
 	// AddValidationError adds the validation error that was generated
-	// when a validation failed.
+	// when a validation failed. The key is used as the field path, prefer
+	// AddFieldValidationError.
 	AddValidationError(key, value string)
+	// AddFieldValidationError adds the validation error that was generated
+	// when a validation failed, see NewValidationError.
+	AddFieldValidationError(vErr ValidationError)
 }
 
 type ParserCtxAccessor interface {
@@ -229,6 +502,69 @@ func SetEnvSliceDelim(delim string) ParserCtxFuncSetter {
 	}
 }
 
+// SetRedactErrors redacts the values of every field from the parse and
+// validation error messages, not only the ones of sensitive fields. Default is
+// false.
+func SetRedactErrors(redact bool) ParserCtxFuncSetter {
+	return func(setter ParserCtxSetter) error {
+		return setter.SetRedactErrors(redact)
+	}
+}
+
+// SetFileEnvSuffix enables reading the value of every field from a file when
+// the env key with the suffix holds its path, e.g. DB_PASSWORD_FILE for
+// DB_PASSWORD when the suffix is DefaultFileEnvSuffix. It is an error to set
+// both env keys. An empty suffix, the default, disables it.
+func SetFileEnvSuffix(suffix string) ParserCtxFuncSetter {
+	return func(setter ParserCtxSetter) error {
+		return setter.SetFileEnvSuffix(suffix)
+	}
+}
+
+// SetFileMaxSize sets the maximum size in bytes of a file that is read for the
+// value of a field. Default is DefaultFileMaxSize, 0 means there is no
+// maximum.
+func SetFileMaxSize(maxSize int64) ParserCtxFuncSetter {
+	return func(setter ParserCtxSetter) error {
+		return setter.SetFileMaxSize(maxSize)
+	}
+}
+
+// SetNestedSliceMaxLen sets the maximum number of elements that are
+// discovered from the env vars for a slice of nested structs, an error is
+// returned when more are found. Default is DefaultNestedSliceMaxLen, 0 means
+// there is no maximum.
+func SetNestedSliceMaxLen(maxLen int) ParserCtxFuncSetter {
+	return func(setter ParserCtxSetter) error {
+		return setter.SetNestedSliceMaxLen(maxLen)
+	}
+}
+
+// SetNestedSliceGapPolicy sets how gaps between the indexes of the env vars of
+// a slice of nested structs are handled. Default is IndexGapStop.
+func SetNestedSliceGapPolicy(policy IndexGapPolicy) ParserCtxFuncSetter {
+	return func(setter ParserCtxSetter) error {
+		return setter.SetNestedSliceGapPolicy(policy)
+	}
+}
+
+// SetFailFast makes Parse return the error of the first field that fails
+// instead of collecting the errors of every field into ParseErrors.
+func SetFailFast(failFast bool) ParserCtxFuncSetter {
+	return func(setter ParserCtxSetter) error {
+		return setter.SetFailFast(failFast)
+	}
+}
+
+// SetValidationAsError sets whether Parse returns a *ValidationErrors error
+// when a validator reported a failure, which is the default. When disabled
+// the failures are only available through GetValidationErrors.
+func SetValidationAsError(asError bool) ParserCtxFuncSetter {
+	return func(setter ParserCtxSetter) error {
+		return setter.SetValidationAsError(asError)
+	}
+}
+
 // SetParserFuncMap sets the ParserFuncMap using the fnMap. This overrides the
 // ParserFuncMap for the given ParserCtx. This should only if it's not desired
 // to use the parsers already defined in this package.
@@ -267,11 +603,147 @@ func AddValidatorFunc(validatorKey string, fn ValidatorFunc) ParserCtxFuncSetter
 	}
 }
 
+// SetParamValidatorFuncsMap sets the ParamValidatorFuncsMap, which overrides
+// the built-in regex validator.
+func SetParamValidatorFuncsMap(fnMap ParamValidatorFuncsMap) ParserCtxFuncSetter {
+	return func(setter ParserCtxSetter) error {
+		return setter.SetParamValidatorFuncsMap(fnMap)
+	}
+}
+
+// AddParamValidatorFunc adds a validator that takes a param, e.g. port_range
+// for validate=port_range=1024-65535. This does not reset the
+// ParamValidatorFuncsMap but will override any existing validator that
+// matches the validatorKey.
+func AddParamValidatorFunc(validatorKey string, fn ParamValidatorFunc) ParserCtxFuncSetter {
+	return func(setter ParserCtxSetter) error {
+		setter.AddParamValidatorFunc(validatorKey, fn)
+		return nil
+	}
+}
+
+// SetTypedValidatorFuncsMap sets the TypedValidatorFuncsMap, which overrides
+// the built-in validators, e.g. min, url or file_exists.
+func SetTypedValidatorFuncsMap(fnMap TypedValidatorFuncsMap) ParserCtxFuncSetter {
+	return func(setter ParserCtxSetter) error {
+		return setter.SetTypedValidatorFuncsMap(fnMap)
+	}
+}
+
+// AddTypedValidatorFunc adds a validator that runs once the field was set and
+// receives its value, e.g. to compare a time.Duration. A rule that matches
+// both a TypedValidatorFunc and another validator runs the
+// TypedValidatorFunc. This does not reset the TypedValidatorFuncsMap but will
+// override any existing validator that matches the validatorKey.
+func AddTypedValidatorFunc(validatorKey string, fn TypedValidatorFunc) ParserCtxFuncSetter {
+	return func(setter ParserCtxSetter) error {
+		setter.AddTypedValidatorFunc(validatorKey, fn)
+		return nil
+	}
+}
+
+// SetCrossFieldValidatorFuncsMap sets the CrossFieldValidatorFuncsMap, which
+// overrides the built-in required_if, required_with, required_without,
+// excluded_with, exactly_one_of and at_least_one_of validators.
+func SetCrossFieldValidatorFuncsMap(fnMap CrossFieldValidatorFuncsMap) ParserCtxFuncSetter {
+	return func(setter ParserCtxSetter) error {
+		return setter.SetCrossFieldValidatorFuncsMap(fnMap)
+	}
+}
+
+// AddCrossFieldValidatorFunc adds a validator that depends on other fields or
+// env vars and runs once every field of the struct was parsed. This does not
+// reset the CrossFieldValidatorFuncsMap but will override any existing
+// validator that matches the validatorKey.
+func AddCrossFieldValidatorFunc(validatorKey string, fn CrossFieldValidatorFunc) ParserCtxFuncSetter {
+	return func(setter ParserCtxSetter) error {
+		setter.AddCrossFieldValidatorFunc(validatorKey, fn)
+		return nil
+	}
+}
+
+// SetResolverMap sets the ResolverMap, which overrides the built-in file and
+// env Resolvers.
+func SetResolverMap(resolverMap ResolverMap) ParserCtxFuncSetter {
+	return func(setter ParserCtxSetter) error {
+		return setter.SetResolverMap(resolverMap)
+	}
+}
+
+// AddResolver adds the Resolver of the scheme, e.g. secret for values like
+// secret://payments/api-key. This does not reset the ResolverMap but will
+// override a Resolver that's already defined for the scheme.
+func AddResolver(scheme string, resolver Resolver) ParserCtxFuncSetter {
+	return func(setter ParserCtxSetter) error {
+		setter.AddResolver(scheme, resolver)
+		return nil
+	}
+}
+
+// SetResolveReferences resolves the references of every field, not only the
+// ones with the resolve tag option. Values whose scheme has no Resolver, e.g.
+// http URLs, are left as is. Default is false.
+func SetResolveReferences(resolve bool) ParserCtxFuncSetter {
+	return func(setter ParserCtxSetter) error {
+		return setter.SetResolveReferences(resolve)
+	}
+}
+
 var defaultParserCtxSetters = []ParserCtxFuncSetter{
 	SetTagName(DefaultTagName),
 	SetEnvPrefixDelim(DefaultEnvPrefixDelim),
 	SetEnvSliceDelim(DefaultEnvSliceDelim),
+	SetNestedSliceMaxLen(DefaultNestedSliceMaxLen),
+	SetFileMaxSize(DefaultFileMaxSize),
+	SetNestedSliceGapPolicy(IndexGapStop),
+	SetValidationAsError(true),
 	SetEnvVarsLoaderFunc(loadEnvVarsToMap),
-	SetParserFuncMap(defaultParserFuncs()),
-	SetValidatorFuncsMap(defaultValidatorsFunc),
+	func(setter ParserCtxSetter) error {
+		// a new map every time so that AddParserFunc doesn't change the
+		// defaults
+		return setter.SetParserFuncMap(defaultParserFuncs())
+	},
+	func(setter ParserCtxSetter) error {
+		// a new map every time so that AddValidatorFunc doesn't change the
+		// defaults
+		fnMap := make(ValidatorFuncsMap, len(defaultValidatorsFunc))
+		for k, fn := range defaultValidatorsFunc {
+			fnMap.Add(k, fn)
+		}
+		return setter.SetValidatorFuncsMap(fnMap)
+	},
+	func(setter ParserCtxSetter) error {
+		// a new map every time so that AddParamValidatorFunc doesn't change
+		// the defaults
+		fnMap := make(ParamValidatorFuncsMap, len(defaultParamValidatorsFunc))
+		for k, fn := range defaultParamValidatorsFunc {
+			fnMap.Add(k, fn)
+		}
+		return setter.SetParamValidatorFuncsMap(fnMap)
+	},
+	func(setter ParserCtxSetter) error {
+		// a new map every time so that AddTypedValidatorFunc doesn't change
+		// the defaults
+		fnMap := make(TypedValidatorFuncsMap, len(defaultTypedValidatorsFunc)+len(builtinValidatorsFunc))
+		for k, fn := range defaultTypedValidatorsFunc {
+			fnMap.Add(k, fn)
+		}
+		for k, fn := range builtinValidatorsFunc {
+			fnMap.Add(k, fn)
+		}
+		return setter.SetTypedValidatorFuncsMap(fnMap)
+	},
+	func(setter ParserCtxSetter) error {
+		// a new map every time so that AddCrossFieldValidatorFunc doesn't
+		// change the defaults
+		fnMap := make(CrossFieldValidatorFuncsMap, len(defaultCrossFieldValidatorsFunc))
+		for k, fn := range defaultCrossFieldValidatorsFunc {
+			fnMap.Add(k, fn)
+		}
+		return setter.SetCrossFieldValidatorFuncsMap(fnMap)
+	},
+	func(setter ParserCtxSetter) error {
+		// a new map every time so that AddResolver doesn't change the defaults
+		return setter.SetResolverMap(defaultResolvers())
+	},
 }
@@ -2,6 +2,8 @@ package envar
 
 import (
 	"fmt"
+	"net"
+	"net/netip"
 	"net/url"
 	"os"
 	"reflect"
@@ -22,7 +24,12 @@ func (p ParserFuncMap) GetLength() int {
 	return len(p)
 }
 
+// Get returns the ParserFunc of rType, or of the type it points to. The
+// ParserFunc of a pointer type, e.g. *time.Location, takes precedence.
 func (p ParserFuncMap) Get(rType reflect.Type) ParserFunc {
+	if f := p.getPtr(rType); f != nil {
+		return f
+	}
 	typeStr := rType.String()
 	if rType.Kind() == reflect.Ptr {
 		typeStr = rType.Elem().String()
@@ -34,6 +41,32 @@ func (p ParserFuncMap) Get(rType reflect.Type) ParserFunc {
 	return f
 }
 
+// getPtr returns the ParserFunc of the pointer type rType itself, which
+// returns the pointer rather than the value it points to, nil if there is
+// none.
+func (p ParserFuncMap) getPtr(rType reflect.Type) ParserFunc {
+	if rType.Kind() != reflect.Ptr {
+		return nil
+	}
+	return p[strings.ToLower(rType.String())]
+}
+
+// parsedValue returns the value returned by a ParserFunc as a value of rType.
+// A pointer returned by the ParserFunc of a pointer type is kept as is, any
+// other value is converted, and copied to a new pointer if rType is one.
+func parsedValue(val interface{}, rType reflect.Type) reflect.Value {
+	v := reflect.ValueOf(val)
+	if rType.Kind() != reflect.Ptr {
+		return v.Convert(rType)
+	}
+	if v.Type() == rType {
+		return v
+	}
+	ptr := reflect.New(rType.Elem())
+	ptr.Elem().Set(v.Convert(rType.Elem()))
+	return ptr
+}
+
 func (p ParserFuncMap) Add(rType reflect.Type, pFunc ParserFunc) {
 	p[strings.ToLower(rType.String())] = pFunc
 }
@@ -124,12 +157,22 @@ func defaultParserFuncs() ParserFuncMap {
 			return *f, nil
 		},
 		reflect.TypeOf(time.Nanosecond): func(v string) (interface{}, error) {
-			d, err := time.ParseDuration(strings.TrimSpace(v))
+			d, err := ParseDuration(v)
 			if err != nil {
 				return nil, errorx.New(fmt.Sprintf("unable to parse duration: %v", err.Error()))
 			}
 			return d, err
 		},
+		timeType:                           parseTime(DefaultTimeLayout),
+		reflect.TypeOf(net.IP{}):           parseIP,
+		reflect.TypeOf(net.IPNet{}):        parseIPNet,
+		reflect.TypeOf(net.HardwareAddr{}): parseHardwareAddr,
+		reflect.TypeOf(net.TCPAddr{}):      parseTCPAddr,
+		reflect.TypeOf(net.UDPAddr{}):      parseUDPAddr,
+		reflect.TypeOf(netip.Addr{}):       parseAddr,
+		reflect.TypeOf(netip.Prefix{}):     parsePrefix,
+		reflect.TypeOf(netip.AddrPort{}):   parseAddrPort,
+		locationType:                       parseLocation,
 	}
 
 	defFuncs := make(ParserFuncMap)
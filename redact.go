@@ -0,0 +1,114 @@
+package envar
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/neumachen/errorx"
+	"github.com/neumachen/gobag"
+)
+
+// Redacted is like Marshal but the values of sensitive fields, the ones with
+// the sensitive tag option or of type Secret, are replaced by RedactedValue.
+// Empty values are left as is.
+func Redacted(v interface{}, setterFuncs ...ParserCtxFuncSetter) (EnvVarsMap, error) {
+	return marshalStruct(v, true, setterFuncs...)
+}
+
+// Dump writes the env vars of the struct, or pointer to struct, v to w as
+// KEY=VALUE lines sorted by key, with the values of sensitive fields redacted.
+// See Redacted.
+func Dump(w io.Writer, v interface{}, setterFuncs ...ParserCtxFuncSetter) error {
+	eMap, err := Redacted(v, setterFuncs...)
+	if err != nil {
+		return err
+	}
+	return writeEnviron(w, eMap)
+}
+
+// RedactEnvVarsMap returns a copy of the eMap, e.g. the env vars that were
+// loaded for Parse, in which the values of the keys of the sensitive fields of
+// the struct, or pointer to struct, v are replaced by RedactedValue.
+func RedactEnvVarsMap(eMap EnvVarsMap, v interface{}, setterFuncs ...ParserCtxFuncSetter) (EnvVarsMap, error) {
+	docs, err := describeStruct(v, redactIndexMarker, setterFuncs...)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(docs))
+	for i := range docs {
+		if !docs[i].Sensitive {
+			continue
+		}
+		parts := strings.Split(docs[i].EnvKey, redactIndexMarker)
+		for j := range parts {
+			parts[j] = regexp.QuoteMeta(parts[j])
+		}
+		keys = append(keys, strings.Join(parts, "(?:0|[1-9][0-9]*)"))
+	}
+
+	redacted := make(EnvVarsMap, len(eMap))
+	for k, v := range eMap {
+		redacted.Set(k, v)
+	}
+	if len(keys) == 0 {
+		return redacted, nil
+	}
+
+	sensitiveKey, err := regexp.Compile("^(?:" + strings.Join(keys, "|") + ")$")
+	if err != nil {
+		return nil, errorx.New(err)
+	}
+	for k, v := range redacted {
+		if sensitiveKey.MatchString(k) && !gobag.StringIsEmpty(v) {
+			redacted.Set(k, RedactedValue)
+		}
+	}
+	return redacted, nil
+}
+
+// redactIndexMarker stands in for the index of a slice of nested structs in
+// the described env keys, it can't be part of an env key.
+const redactIndexMarker = "\x00"
+
+func writeEnviron(w io.Writer, eMap EnvVarsMap) error {
+	environ := eMap.Environ()
+	for i := range environ {
+		if _, err := fmt.Fprintln(w, environ[i]); err != nil {
+			return errorx.New(err)
+		}
+	}
+	return nil
+}
+
+// isSensitive reports whether the value of the field must not be printed.
+func (p *parsedField) isSensitive() bool {
+	return p.sensitive || p.tagOpts.getSensitive() || isSecretType(p.GetStructField().Type)
+}
+
+// redactErrors reports whether the value must be kept out of error messages.
+func (p *parsedField) redactErrors() bool {
+	return p.redactAll || p.isSensitive()
+}
+
+// redactValue replaces the value of the field in s when it must be kept out
+// of error messages.
+func (p *parsedField) redactValue(s string) string {
+	if !p.redactErrors() {
+		return s
+	}
+	return redactString(s, p.getFieldValue())
+}
+
+// redactElem returns v, e.g. an element of a slice that a validator puts in
+// its message, or RedactedValue when the value of the field must be kept out
+// of error messages. Only the whole value is redacted from the messages by the
+// ctx.
+func redactElem(parsedField ParsedFieldGetter, v string) string {
+	if parsedField.redactErrors() {
+		return RedactedValue
+	}
+	return v
+}
@@ -0,0 +1,180 @@
+package envar
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse_RedactParseError(t *testing.T) {
+	type config struct {
+		Pin     int            `env:"PIN,sensitive"`
+		Token   Secret[int]    `env:"TOKEN"`
+		Ports   []int          `env:"PORTS,sensitive"`
+		Limits  map[string]int `env:"LIMITS,sensitive"`
+		Visible int            `env:"VISIBLE"`
+	}
+
+	_, err := Parse(&config{}, SetEnvVarsLoaderFunc(EnvVarsMapLoaderFunc(EnvVarsMap{
+		"PIN":     "hunter2",
+		"TOKEN":   "t0ken",
+		"PORTS":   "80,p4ss",
+		"LIMITS":  "a:l1m1t",
+		"VISIBLE": "shown",
+	})))
+	require.Error(t, err)
+
+	msg := err.Error()
+	for _, secret := range []string{"hunter2", "t0ken", "p4ss", "l1m1t"} {
+		require.NotContains(t, msg, secret)
+	}
+	require.Contains(t, msg, `parsing "******": invalid syntax`)
+	require.Contains(t, msg, `parsing "shown": invalid syntax`)
+
+	var parseErrs ParseErrors
+	require.ErrorAs(t, err, &parseErrs)
+	require.Len(t, parseErrs, 5)
+
+	parseErr := &ParseError{}
+	require.ErrorAs(t, parseErrs[0], &parseErr)
+	require.Equal(t, "Pin", parseErr.FieldName)
+	require.True(t, parseErr.Redacted)
+	require.Equal(t, "hunter2", parseErr.Value())
+	require.Contains(t, parseErr.Unredacted().Error(), "hunter2")
+	require.NotContains(t, errors.Unwrap(parseErr).Error(), "hunter2")
+
+	// the original error can still be matched
+	require.ErrorIs(t, parseErrs[0], strconv.ErrSyntax)
+	numErr := &strconv.NumError{}
+	require.ErrorAs(t, parseErrs[0], &numErr)
+
+	require.ErrorAs(t, parseErrs[4], &parseErr)
+	require.Equal(t, "Visible", parseErr.FieldName)
+	require.False(t, parseErr.Redacted)
+}
+
+func TestParse_SetRedactErrors(t *testing.T) {
+	type config struct {
+		Port int `env:"PORT"`
+	}
+	loader := SetEnvVarsLoaderFunc(EnvVarsMapLoaderFunc(EnvVarsMap{"PORT": "hunter2"}))
+
+	_, err := Parse(&config{}, loader)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "hunter2")
+
+	_, err = Parse(&config{}, loader, SetRedactErrors(true))
+	require.Error(t, err)
+	require.NotContains(t, err.Error(), "hunter2")
+}
+
+func TestParse_RedactValidationError(t *testing.T) {
+	type config struct {
+		Password string `env:"PASSWORD,sensitive,validate=no_spaces|failing"`
+		Name     string `env:"NAME,validate=no_spaces"`
+	}
+
+	noSpaces := func(parserCtx ParserCtxAccessor, parsedField ParsedFieldGetter) error {
+		if strings.Contains(parsedField.GetEnvValue(), " ") {
+			parserCtx.AddFieldValidationError(NewValidationError(
+				parsedField,
+				"value "+strconv.Quote(parsedField.GetEnvValue())+" contains spaces",
+			))
+		}
+		return nil
+	}
+	failing := func(parserCtx ParserCtxAccessor, parsedField ParsedFieldGetter) error {
+		return errors.New("unable to check " + parsedField.GetEnvValue())
+	}
+
+	loader := SetEnvVarsLoaderFunc(EnvVarsMapLoaderFunc(EnvVarsMap{
+		"PASSWORD": "hunter 2",
+		"NAME":     "john doe",
+	}))
+	_, err := Parse(
+		&config{},
+		loader,
+		SetFailFast(true),
+		SetValidatorFuncsMap(ValidatorFuncsMap{"no_spaces": noSpaces, "failing": failing}),
+	)
+	require.Error(t, err)
+	require.NotContains(t, err.Error(), "hunter 2")
+	require.Contains(t, err.Error(), "unable to check ******")
+
+	ctx, err := Parse(
+		&config{},
+		loader,
+		SetValidatorFuncsMap(ValidatorFuncsMap{"no_spaces": noSpaces, "failing": func(ParserCtxAccessor, ParsedFieldGetter) error { return nil }}),
+	)
+	require.Error(t, err)
+	valErrs := ctx.GetValidationErrors()
+	require.Equal(t, `value "******" contains spaces`, valErrs.Get("config.Password")[0].Message)
+	require.Equal(t, `value "john doe" contains spaces`, valErrs.Get("config.Name")[0].Message)
+}
+
+func TestParse_RedactShortValue(t *testing.T) {
+	type config struct {
+		Token string `env:"TOKEN,sensitive,validate=min=3"`
+		Port  int    `env:"PORT,sensitive"`
+	}
+
+	// a value that is only part of a word is left alone
+	_, err := Parse(&config{}, SetEnvVarsLoaderFunc(EnvVarsMapLoaderFunc(EnvVarsMap{"PORT": "e"})))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `strconv.ParseInt: parsing "******": invalid syntax`)
+
+	ctx, err := Parse(&config{}, SetEnvVarsLoaderFunc(EnvVarsMapLoaderFunc(EnvVarsMap{"TOKEN": "e"})))
+	require.Error(t, err)
+	require.Equal(
+		t,
+		"env key: TOKEN value length 1 is less than 3",
+		ctx.GetValidationErrors().Get("config.Token")[0].Message,
+	)
+}
+
+func TestParse_RedactExpandError(t *testing.T) {
+	type config struct {
+		Password string `env:"PASSWORD,sensitive,expand"`
+		DSN      string `env:"DSN,expand"`
+	}
+
+	_, err := Parse(&config{}, SetEnvVarsLoaderFunc(EnvVarsMapLoaderFunc(EnvVarsMap{
+		"PASSWORD": "hunter2${oops",
+	})))
+	require.Error(t, err)
+	require.NotContains(t, err.Error(), "oops")
+	require.Contains(t, err.Error(), "unterminated variable reference: ******")
+
+	_, err = Parse(&config{}, SetEnvVarsLoaderFunc(EnvVarsMapLoaderFunc(EnvVarsMap{
+		"PASSWORD": "hunter2${1}",
+	})))
+	require.Error(t, err)
+	require.NotContains(t, err.Error(), "${1}")
+	require.Contains(t, err.Error(), "bad substitution: ******")
+
+	_, err = Parse(&config{}, SetEnvVarsLoaderFunc(EnvVarsMapLoaderFunc(EnvVarsMap{
+		"DSN": "postgres://${oops",
+	})))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "unterminated variable reference: ${oops")
+}
+
+func TestRedactString(t *testing.T) {
+	testData := []struct {
+		s        string
+		value    string
+		expected string
+	}{
+		{s: `parsing "e": invalid syntax`, value: "e", expected: `parsing "******": invalid syntax`},
+		{s: "env key: TOKEN value is empty", value: "e", expected: "env key: TOKEN value is empty"},
+		{s: "unable to check hunter 2", value: "hunter 2", expected: "unable to check ******"},
+		{s: "value s3cret, s3cret2 and s3cret", value: "s3cret", expected: "value ******, s3cret2 and ******"},
+		{s: "address :8080: invalid", value: ":8080", expected: "address ******: invalid"},
+	}
+	for i := range testData {
+		require.Equal(t, testData[i].expected, redactString(testData[i].s, testData[i].value), testData[i].s)
+	}
+}
@@ -0,0 +1,114 @@
+package envar
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/neumachen/errorx"
+	"github.com/neumachen/gobag"
+)
+
+// Resolver resolves a reference, e.g. file:///run/secrets/db, into the value
+// it refers to. Resolvers are registered by scheme with AddResolver.
+type Resolver interface {
+	Resolve(parserCtx ParserCtxGetter, ref *url.URL) (string, error)
+}
+
+// ResolverFunc is an adapter to use a func as a Resolver.
+type ResolverFunc func(parserCtx ParserCtxGetter, ref *url.URL) (string, error)
+
+func (f ResolverFunc) Resolve(parserCtx ParserCtxGetter, ref *url.URL) (string, error) {
+	return f(parserCtx, ref)
+}
+
+// ResolverMap holds the Resolvers keyed by scheme.
+type ResolverMap map[string]Resolver
+
+func (r ResolverMap) GetLength() int {
+	return len(r)
+}
+
+// Get returns the Resolver of the scheme, which is case insensitive.
+func (r ResolverMap) Get(scheme string) (Resolver, bool) {
+	resolver, ok := r[strings.ToLower(scheme)]
+	return resolver, ok
+}
+
+func (r ResolverMap) Add(scheme string, resolver Resolver) {
+	r[strings.ToLower(scheme)] = resolver
+}
+
+// defaultResolvers returns the built-in resolvers:
+//   - file:///path/to/file is replaced by the trimmed contents of the file,
+//     up to the size set with SetFileMaxSize.
+//   - env://KEY is replaced by the value of the env var KEY.
+func defaultResolvers() ResolverMap {
+	return ResolverMap{
+		"file": ResolverFunc(resolveFile),
+		"env":  ResolverFunc(resolveEnv),
+	}
+}
+
+func resolveFile(parserCtx ParserCtxGetter, ref *url.URL) (string, error) {
+	// file://relative/path has the first element of the path as its host
+	path := ref.Host + ref.Path
+	if gobag.StringIsEmpty(path) {
+		return "", errorx.New("file path is empty")
+	}
+	return readFileContents(path, parserCtx.GetFileMaxSize())
+}
+
+func resolveEnv(parserCtx ParserCtxGetter, ref *url.URL) (string, error) {
+	key := ref.Host + strings.TrimPrefix(ref.Path, "/")
+	v, ok := parserCtx.GetEnvVarsMap().Get(key)
+	if !ok {
+		return "", errorx.New(fmt.Sprintf("env key: %s not found", key))
+	}
+	return v, nil
+}
+
+var referenceRegexp = regexp.MustCompile(`^([a-zA-Z][a-zA-Z0-9+.-]*)://`)
+
+// resolve replaces a reference with the value it refers to when the field has
+// the resolve tag option or SetResolveReferences is enabled. With the tag
+// option a reference to a scheme without a Resolver is an error, otherwise it
+// is left as is, e.g. an http URL.
+func (p *parsedField) resolve(parserCtx *ParserCtx) error {
+	explicit := p.tagOpts.getResolveKey()
+	if !explicit && !parserCtx.GetResolveReferences() {
+		return nil
+	}
+
+	v := p.getFieldValue()
+	match := referenceRegexp.FindStringSubmatch(v)
+	if match == nil {
+		return nil
+	}
+	resolver, ok := parserCtx.GetResolverMap().Get(match[1])
+	if !ok {
+		if explicit {
+			return errorx.New(fmt.Sprintf("env: no resolver found for scheme %s of env key %s", match[1], p.GetEnvKey()))
+		}
+		return nil
+	}
+
+	ref, err := url.Parse(v)
+	if err != nil {
+		return errorx.New(fmt.Sprintf("env: unable to parse reference of env key %s: %v", p.GetEnvKey(), err))
+	}
+	resolved, err := resolver.Resolve(parserCtx, ref)
+	if err != nil {
+		return errorx.New(fmt.Sprintf("env: unable to resolve %s reference of env key %s: %v", match[1], p.GetEnvKey(), err))
+	}
+
+	// a default that is a reference is replaced by its value so that the env
+	// var is still reported as not set
+	if gobag.StringIsEmpty(p.GetEnvValue()) {
+		p.setTagOpts(tagOptsDefaultKey, resolved)
+		return nil
+	}
+	p.envValue = resolved
+	return nil
+}
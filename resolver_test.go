@@ -0,0 +1,120 @@
+package envar
+
+import (
+	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// stubResolver resolves references from memory, e.g. in place of a vault
+// client.
+type stubResolver map[string]string
+
+func (s stubResolver) Resolve(_ ParserCtxGetter, ref *url.URL) (string, error) {
+	v, ok := s[ref.Host+ref.Path]
+	if !ok {
+		return "", errors.New("secret not found")
+	}
+	return v, nil
+}
+
+func TestParse_Resolve(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "db")
+	require.NoError(t, os.WriteFile(path, []byte("db-pass\n"), 0o600))
+
+	type config struct {
+		APIKey   string `env:"API_KEY,resolve,validate=required"`
+		DBPass   string `env:"DB_PASS,resolve"`
+		Alias    string `env:"ALIAS,resolve"`
+		Plain    string `env:"PLAIN,resolve"`
+		Port     int    `env:"PORT,resolve,default=secret://port"`
+		Endpoint string `env:"ENDPOINT"`
+	}
+
+	cfg := config{}
+	_, err := Parse(
+		&cfg,
+		AddResolver("secret", stubResolver{"payments/api-key": "k3y", "port": "8443"}),
+		SetEnvVarsLoaderFunc(EnvVarsMapLoaderFunc(EnvVarsMap{
+			"API_KEY":  "secret://payments/api-key",
+			"DB_PASS":  "file://" + path,
+			"ALIAS":    "env://ENDPOINT",
+			"PLAIN":    "plain value",
+			"ENDPOINT": "secret://not/resolved",
+		})),
+	)
+	require.NoError(t, err)
+	require.Equal(t, config{
+		APIKey:   "k3y",
+		DBPass:   "db-pass",
+		Alias:    "secret://not/resolved",
+		Plain:    "plain value",
+		Port:     8443,
+		Endpoint: "secret://not/resolved",
+	}, cfg)
+
+	errData := []struct {
+		eMap        EnvVarsMap
+		errContains string
+	}{
+		{
+			eMap:        EnvVarsMap{"API_KEY": "vault://payments/api-key"},
+			errContains: "no resolver found for scheme vault of env key API_KEY",
+		},
+		{
+			eMap:        EnvVarsMap{"API_KEY": "secret://payments/missing"},
+			errContains: "unable to resolve secret reference of env key API_KEY: secret not found",
+		},
+		{
+			eMap:        EnvVarsMap{"ALIAS": "env://MISSING"},
+			errContains: "env key: MISSING not found",
+		},
+		{
+			eMap:        EnvVarsMap{"DB_PASS": "file://" + filepath.Join(t.TempDir(), "missing")},
+			errContains: "unable to resolve file reference of env key DB_PASS",
+		},
+	}
+	for i := range errData {
+		_, err := Parse(
+			&config{},
+			AddResolver("secret", stubResolver{"port": "1"}),
+			SetEnvVarsLoaderFunc(EnvVarsMapLoaderFunc(errData[i].eMap)),
+		)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), errData[i].errContains)
+	}
+}
+
+func TestParse_SetResolveReferences(t *testing.T) {
+	type config struct {
+		Token    string `env:"TOKEN"`
+		Endpoint string `env:"ENDPOINT"`
+	}
+	loader := SetEnvVarsLoaderFunc(EnvVarsMapLoaderFunc(EnvVarsMap{
+		"TOKEN":    "secret://token",
+		"ENDPOINT": "https://example.com",
+	}))
+	resolver := AddResolver("SECRET", ResolverFunc(func(_ ParserCtxGetter, ref *url.URL) (string, error) {
+		return "resolved " + ref.Host, nil
+	}))
+
+	cfg := config{}
+	_, err := Parse(&cfg, loader, resolver)
+	require.NoError(t, err)
+	require.Equal(t, "secret://token", cfg.Token)
+
+	cfg = config{}
+	_, err = Parse(&cfg, loader, resolver, SetResolveReferences(true))
+	require.NoError(t, err)
+	require.Equal(t, config{Token: "resolved token", Endpoint: "https://example.com"}, cfg)
+
+	// the resolvers of one parse are not shared with the next one
+	parserCtx, err := Parse(&config{}, loader)
+	require.NoError(t, err)
+	_, ok := parserCtx.GetResolverMap().Get("secret")
+	require.False(t, ok)
+}
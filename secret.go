@@ -0,0 +1,95 @@
+package envar
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// RedactedValue replaces the value of a sensitive field wherever it would
+// otherwise be printed.
+const RedactedValue = "******"
+
+// Secret holds a value that is never printed, it is redacted by String,
+// GoString, MarshalJSON, MarshalText and every fmt verb. Use Get to access the
+// value. Parse populates a Secret field like a field of type T and Marshal
+// emits the real value, a Secret field is always treated as sensitive.
+type Secret[T any] struct {
+	value T
+}
+
+// NewSecret returns a Secret holding v.
+func NewSecret[T any](v T) Secret[T] {
+	return Secret[T]{value: v}
+}
+
+// Get returns the value of the secret.
+func (s Secret[T]) Get() T {
+	return s.value
+}
+
+func (s Secret[T]) String() string {
+	return RedactedValue
+}
+
+func (s Secret[T]) GoString() string {
+	return fmt.Sprintf("envar.Secret[%T](%s)", s.value, RedactedValue)
+}
+
+// Format redacts the value for every verb, including %+v and %#v.
+func (s Secret[T]) Format(f fmt.State, verb rune) {
+	if verb == 'v' && f.Flag('#') {
+		_, _ = io.WriteString(f, s.GoString())
+		return
+	}
+	_, _ = io.WriteString(f, RedactedValue)
+}
+
+func (s Secret[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(RedactedValue)
+}
+
+func (s Secret[T]) MarshalText() ([]byte, error) {
+	return []byte(RedactedValue), nil
+}
+
+func (s Secret[T]) secretValue() reflect.Value {
+	return reflect.ValueOf(&s.value).Elem()
+}
+
+func (s *Secret[T]) secretPtr() reflect.Value {
+	return reflect.ValueOf(&s.value).Elem()
+}
+
+// secretValuer is implemented by Secret, it gives access to the value for
+// Marshal.
+type secretValuer interface {
+	secretValue() reflect.Value
+}
+
+// secretSetter is implemented by a pointer to Secret, it gives access to the
+// value for Parse.
+type secretSetter interface {
+	secretPtr() reflect.Value
+}
+
+var secretSetterType = reflect.TypeOf((*secretSetter)(nil)).Elem()
+
+// isSecretType reports whether rType, or the type it points to, is a Secret.
+func isSecretType(rType reflect.Type) bool {
+	if rType.Kind() == reflect.Ptr {
+		rType = rType.Elem()
+	}
+	return reflect.PtrTo(rType).Implements(secretSetterType)
+}
+
+// setSecret parses the value held by the Secret fieldValue as if it was a
+// field of its type.
+func (p *parsedField) setSecret(parserCtx *ParserCtx, fieldValue reflect.Value) error {
+	value := fieldValue.Addr().Interface().(secretSetter).secretPtr()
+	valueField := *p
+	valueField.structField.Type = value.Type()
+	valueField.sensitive = true
+	return valueField.setField(parserCtx, value)
+}
@@ -0,0 +1,130 @@
+package envar
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type secretDatabase struct {
+	Host     string `env:"HOST"`
+	Password string `env:"PASSWORD,sensitive"`
+}
+
+type secretConfig struct {
+	Name     string            `env:"NAME"`
+	Token    Secret[string]    `env:"TOKEN"`
+	Port     *Secret[int]      `env:"PORT"`
+	Keys     Secret[[]string]  `env:"KEYS"`
+	Empty    Secret[string]    `env:"EMPTY"`
+	Replicas []secretDatabase  `env:"REPLICAS,nested"`
+	Labels   map[string]string `env:"LABELS,sensitive"`
+	Primary  secretDatabase    `env:"PRIMARY,nested"`
+}
+
+func TestSecret(t *testing.T) {
+	s := NewSecret("hunter2")
+	require.Equal(t, "hunter2", s.Get())
+
+	for _, format := range []string{"%s", "%v", "%+v", "%q", "%d", "%x"} {
+		require.NotContains(t, fmt.Sprintf(format, s), "hunter2", format)
+	}
+	require.Equal(t, RedactedValue, fmt.Sprint(s))
+	require.Equal(t, "envar.Secret[string](******)", fmt.Sprintf("%#v", s))
+
+	wrapped := struct{ Token Secret[string] }{Token: s}
+	require.NotContains(t, fmt.Sprintf("%+v", wrapped), "hunter2")
+	require.NotContains(t, fmt.Sprintf("%#v", wrapped), "hunter2")
+
+	b, err := json.Marshal(wrapped)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"Token": "******"}`, string(b))
+}
+
+func TestParse_Secret(t *testing.T) {
+	cfg := secretConfig{}
+	_, err := Parse(&cfg, SetEnvVarsLoaderFunc(EnvVarsMapLoaderFunc(EnvVarsMap{
+		"TOKEN": "hunter2",
+		"PORT":  "5432",
+		"KEYS":  "a,b",
+	})))
+	require.NoError(t, err)
+	require.Equal(t, "hunter2", cfg.Token.Get())
+	require.Equal(t, 5432, cfg.Port.Get())
+	require.Equal(t, []string{"a", "b"}, cfg.Keys.Get())
+	require.Equal(t, "", cfg.Empty.Get())
+
+	_, err = Parse(&secretConfig{}, SetEnvVarsLoaderFunc(EnvVarsMapLoaderFunc(EnvVarsMap{
+		"PORT": "not a port",
+	})))
+	require.Error(t, err)
+}
+
+func TestRedacted(t *testing.T) {
+	port := NewSecret(5432)
+	cfg := secretConfig{
+		Name:  "app",
+		Token: NewSecret("hunter2"),
+		Port:  &port,
+		Replicas: []secretDatabase{
+			{Host: "replica", Password: "replica-pass"},
+		},
+		Labels:  map[string]string{"team": "core"},
+		Primary: secretDatabase{Host: "primary"},
+	}
+
+	eMap, err := Marshal(cfg)
+	require.NoError(t, err)
+	require.Equal(t, "hunter2", eMap["TOKEN"])
+	require.Equal(t, "5432", eMap["PORT"])
+	require.Equal(t, "replica-pass", eMap["REPLICAS_0_PASSWORD"])
+
+	eMap, err = Redacted(cfg)
+	require.NoError(t, err)
+	require.Equal(t, EnvVarsMap{
+		"NAME":                "app",
+		"TOKEN":               RedactedValue,
+		"PORT":                RedactedValue,
+		"EMPTY":               "",
+		"REPLICAS_0_HOST":     "replica",
+		"REPLICAS_0_PASSWORD": RedactedValue,
+		"LABELS":              RedactedValue,
+		"PRIMARY_HOST":        "primary",
+		"PRIMARY_PASSWORD":    "",
+	}, eMap)
+
+	buf := bytes.Buffer{}
+	require.NoError(t, Dump(&buf, &cfg))
+	require.NotContains(t, buf.String(), "hunter2")
+	require.Contains(t, buf.String(), "NAME=app\n")
+	require.Contains(t, buf.String(), "TOKEN=******\n")
+}
+
+func TestRedactEnvVarsMap(t *testing.T) {
+	eMap := EnvVarsMap{
+		"NAME":                 "app",
+		"TOKEN":                "hunter2",
+		"REPLICAS_0_PASSWORD":  "pass0",
+		"REPLICAS_12_PASSWORD": "pass12",
+		"REPLICAS_X_PASSWORD":  "not indexed",
+		"PRIMARY_PASSWORD":     "",
+		"UNRELATED":            "value",
+	}
+
+	redacted, err := RedactEnvVarsMap(eMap, secretConfig{})
+	require.NoError(t, err)
+	require.Equal(t, EnvVarsMap{
+		"NAME":                 "app",
+		"TOKEN":                RedactedValue,
+		"REPLICAS_0_PASSWORD":  RedactedValue,
+		"REPLICAS_12_PASSWORD": RedactedValue,
+		"REPLICAS_X_PASSWORD":  "not indexed",
+		"PRIMARY_PASSWORD":     "",
+		"UNRELATED":            "value",
+	}, redacted)
+	// the original is left untouched
+	require.Equal(t, "hunter2", eMap["TOKEN"])
+}
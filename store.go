@@ -0,0 +1,210 @@
+package envar
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// DefaultWatchInterval is the interval at which WatchFiles polls the files
+// when no interval is given.
+const DefaultWatchInterval = 5 * time.Second
+
+// StoreSubscriberFunc is called with the previous and the new value every
+// time a Store was reloaded successfully.
+type StoreSubscriberFunc[T any] func(old, new *T)
+
+// Store holds a struct of type T that is parsed with Parse and can be
+// reloaded while it is being used, e.g. to change the log level of a long
+// running process without restarting it. The value is swapped atomically and
+// is only replaced when the reload succeeded, validation included.
+//
+// The ParserCtxFuncSetters are applied on every reload, which means that
+// dotenv files set with SetDotEnvFile or SetEnvVarsLoaderChain are read again.
+type Store[T any] struct {
+	value       atomic.Pointer[T]
+	setterFuncs []ParserCtxFuncSetter
+
+	// reloadMu serializes the reloads so that subscribers are notified in
+	// order, mu guards the subscribers and the errFuncs so that they can be
+	// changed while they are being called
+	reloadMu    sync.Mutex
+	mu          sync.Mutex
+	nextID      int
+	subscribers map[int]StoreSubscriberFunc[T]
+	errFuncs    []func(err error)
+}
+
+// NewStore returns a Store holding the value parsed using the setterFuncs. An
+// error is returned if the value could not be parsed or validated.
+func NewStore[T any](setterFuncs ...ParserCtxFuncSetter) (*Store[T], error) {
+	s := &Store[T]{
+		setterFuncs: setterFuncs,
+		subscribers: make(map[int]StoreSubscriberFunc[T]),
+	}
+	v, err := s.parse()
+	if err != nil {
+		return nil, err
+	}
+	s.value.Store(v)
+	return s, nil
+}
+
+// Load returns the current value, it must not be modified.
+func (s *Store[T]) Load() *T {
+	return s.value.Load()
+}
+
+// Reload parses a new value and swaps it with the current one. The current
+// value is kept and the error is returned if the new value could not be
+// parsed or validated. The subscribers are notified once the value was
+// swapped, they may unsubscribe but must not call Reload.
+func (s *Store[T]) Reload() error {
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+
+	v, err := s.parse()
+	if err != nil {
+		s.mu.Lock()
+		errFuncs := append([]func(err error){}, s.errFuncs...)
+		s.mu.Unlock()
+
+		for i := range errFuncs {
+			errFuncs[i](err)
+		}
+		return err
+	}
+
+	old := s.value.Swap(v)
+
+	s.mu.Lock()
+	subscribers := make([]StoreSubscriberFunc[T], 0, len(s.subscribers))
+	for _, fn := range s.subscribers {
+		subscribers = append(subscribers, fn)
+	}
+	s.mu.Unlock()
+
+	for i := range subscribers {
+		subscribers[i](old, v)
+	}
+	return nil
+}
+
+// Subscribe registers fn to be called after every successful reload. The
+// returned func unregisters it.
+func (s *Store[T]) Subscribe(fn StoreSubscriberFunc[T]) (unsubscribe func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := s.nextID
+	s.nextID++
+	s.subscribers[id] = fn
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		delete(s.subscribers, id)
+	}
+}
+
+// OnError registers fn to be called when a reload failed, e.g. to log the
+// errors of the reloads triggered by WatchSignals and WatchFiles.
+func (s *Store[T]) OnError(fn func(err error)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.errFuncs = append(s.errFuncs, fn)
+}
+
+// WatchSignals reloads the value every time one of the signals, SIGHUP if
+// none are given, is received. It blocks until the ctx is done.
+func (s *Store[T]) WatchSignals(ctx context.Context, signals ...os.Signal) {
+	if len(signals) == 0 {
+		signals = []os.Signal{syscall.SIGHUP}
+	}
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, signals...)
+	defer signal.Stop(sigCh)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-sigCh:
+			_ = s.Reload()
+		}
+	}
+}
+
+// WatchFiles polls the files found at the paths, e.g. dotenv or secret files,
+// every interval and reloads the value when one of them was created, removed
+// or modified. DefaultWatchInterval is used if the interval is not positive.
+// It blocks until the ctx is done.
+func (s *Store[T]) WatchFiles(ctx context.Context, interval time.Duration, paths ...string) {
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	states := make([]fileState, len(paths))
+	for i := range paths {
+		states[i] = statFile(paths[i])
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			changed := false
+			for i := range paths {
+				state := statFile(paths[i])
+				if !state.equal(states[i]) {
+					states[i] = state
+					changed = true
+				}
+			}
+			if changed {
+				_ = s.Reload()
+			}
+		}
+	}
+}
+
+func (s *Store[T]) parse() (*T, error) {
+	v, parserCtx, err := ParseAs[T](s.setterFuncs...)
+	if err != nil {
+		return nil, err
+	}
+	// validation errors are not returned by Parse when
+	// SetValidationAsError(false) is used but the value is still invalid
+	if parserCtx.HasValidationErrors() {
+		return nil, &ValidationErrors{Errors: parserCtx.GetValidationErrors()}
+	}
+	return &v, nil
+}
+
+// fileState is what WatchFiles compares to detect a change.
+type fileState struct {
+	exists  bool
+	size    int64
+	modTime time.Time
+}
+
+func (f fileState) equal(other fileState) bool {
+	return f.exists == other.exists && f.size == other.size && f.modTime.Equal(other.modTime)
+}
+
+func statFile(path string) fileState {
+	fileInfo, err := os.Stat(path)
+	if err != nil {
+		return fileState{}
+	}
+	return fileState{
+		exists:  true,
+		size:    fileInfo.Size(),
+		modTime: fileInfo.ModTime(),
+	}
+}
@@ -0,0 +1,57 @@
+//go:build unix
+
+package envar
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore_WatchSignals(t *testing.T) {
+	env := &storeEnv{eMap: EnvVarsMap{"LOG_LEVEL": "info"}}
+	store, err := NewStore[storeConfig](SetEnvVarsLoaderFunc(env.load))
+	require.NoError(t, err)
+
+	reloaded := make(chan *storeConfig, 1)
+	store.Subscribe(func(_, new *storeConfig) {
+		reloaded <- new
+	})
+
+	// SIGUSR1 terminates the process unless it is being notified, which
+	// might not yet be the case for the watcher when the first one is sent
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGUSR1)
+	defer signal.Stop(sigCh)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		store.WatchSignals(ctx, syscall.SIGUSR1)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	env.set(EnvVarsMap{"LOG_LEVEL": "debug"})
+	// the signal is sent until it was received by the watcher
+	deadline := time.After(5 * time.Second)
+	for {
+		require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGUSR1))
+		select {
+		case v := <-reloaded:
+			require.Equal(t, "debug", v.LogLevel)
+			return
+		case <-deadline:
+			t.Fatal("the store was not reloaded")
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package envar
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type storeConfig struct {
+	LogLevel  string `env:"LOG_LEVEL,validate=required"`
+	RateLimit int    `env:"RATE_LIMIT"`
+}
+
+// storeEnv is an EnvVarsLoaderFunc whose EnvVarsMap can be replaced while a
+// Store is using it.
+type storeEnv struct {
+	mu   sync.Mutex
+	eMap EnvVarsMap
+}
+
+func (e *storeEnv) set(eMap EnvVarsMap) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.eMap = eMap
+}
+
+func (e *storeEnv) load() EnvVarsMap {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.eMap
+}
+
+func TestStore(t *testing.T) {
+	env := &storeEnv{eMap: EnvVarsMap{"LOG_LEVEL": "info", "RATE_LIMIT": "10"}}
+	store, err := NewStore[storeConfig](SetEnvVarsLoaderFunc(env.load))
+	require.NoError(t, err)
+	require.Equal(t, &storeConfig{LogLevel: "info", RateLimit: 10}, store.Load())
+
+	var olds, news []*storeConfig
+	unsubscribe := store.Subscribe(func(old, new *storeConfig) {
+		olds = append(olds, old)
+		news = append(news, new)
+	})
+	var errs []error
+	store.OnError(func(err error) {
+		errs = append(errs, err)
+	})
+
+	first := store.Load()
+	env.set(EnvVarsMap{"LOG_LEVEL": "debug", "RATE_LIMIT": "20"})
+	require.NoError(t, store.Reload())
+	require.Equal(t, &storeConfig{LogLevel: "debug", RateLimit: 20}, store.Load())
+	require.Equal(t, []*storeConfig{first}, olds)
+	require.Equal(t, []*storeConfig{store.Load()}, news)
+	// the previous value is left untouched
+	require.Equal(t, "info", first.LogLevel)
+
+	// the value is kept when the new one is invalid
+	env.set(EnvVarsMap{"RATE_LIMIT": "30"})
+	err = store.Reload()
+	validationErrs := &ValidationErrors{}
+	require.ErrorAs(t, err, &validationErrs)
+	require.Equal(t, "debug", store.Load().LogLevel)
+	require.Len(t, olds, 1)
+	require.Equal(t, []error{err}, errs)
+
+	env.set(EnvVarsMap{"LOG_LEVEL": "warn", "RATE_LIMIT": "NaN"})
+	require.Error(t, store.Reload())
+	require.Equal(t, "debug", store.Load().LogLevel)
+
+	unsubscribe()
+	env.set(EnvVarsMap{"LOG_LEVEL": "error"})
+	require.NoError(t, store.Reload())
+	require.Equal(t, "error", store.Load().LogLevel)
+	require.Len(t, olds, 1)
+}
+
+func TestStore_UnsubscribeFromSubscriber(t *testing.T) {
+	env := &storeEnv{eMap: EnvVarsMap{"LOG_LEVEL": "info"}}
+	store, err := NewStore[storeConfig](SetEnvVarsLoaderFunc(env.load))
+	require.NoError(t, err)
+
+	calls := 0
+	var unsubscribe func()
+	unsubscribe = store.Subscribe(func(old, new *storeConfig) {
+		calls++
+		unsubscribe()
+	})
+	store.OnError(func(err error) {
+		store.OnError(func(err error) {})
+	})
+
+	require.NoError(t, store.Reload())
+	require.NoError(t, store.Reload())
+	require.Equal(t, 1, calls)
+
+	env.set(EnvVarsMap{})
+	require.Error(t, store.Reload())
+}
+
+func TestNewStore_Error(t *testing.T) {
+	_, err := NewStore[storeConfig](SetEnvVarsLoaderFunc(EnvVarsMapLoaderFunc(EnvVarsMap{})))
+	validationErrs := &ValidationErrors{}
+	require.ErrorAs(t, err, &validationErrs)
+
+	// validation failures are errors even when they are not returned by Parse
+	_, err = NewStore[storeConfig](
+		SetEnvVarsLoaderFunc(EnvVarsMapLoaderFunc(EnvVarsMap{})),
+		SetValidationAsError(false),
+	)
+	require.ErrorAs(t, err, &validationErrs)
+
+	_, err = NewStore[string]()
+	require.ErrorIs(t, err, ErrNotAStructPtr)
+}
+
+func TestStore_WatchFiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	require.NoError(t, os.WriteFile(path, []byte("LOG_LEVEL=info\n"), 0o600))
+
+	store, err := NewStore[storeConfig](SetDotEnvFile(path))
+	require.NoError(t, err)
+
+	reloaded := make(chan *storeConfig, 1)
+	store.Subscribe(func(_, new *storeConfig) {
+		reloaded <- new
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		store.WatchFiles(ctx, 10*time.Millisecond, path)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	// give the watcher the time to record the initial state
+	time.Sleep(30 * time.Millisecond)
+	require.NoError(t, os.WriteFile(path, []byte("LOG_LEVEL=debug\nRATE_LIMIT=5\n"), 0o600))
+
+	select {
+	case v := <-reloaded:
+		require.Equal(t, &storeConfig{LogLevel: "debug", RateLimit: 5}, v)
+		require.Equal(t, v, store.Load())
+	case <-time.After(5 * time.Second):
+		t.Fatal("the store was not reloaded")
+	}
+}
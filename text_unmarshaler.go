@@ -21,7 +21,7 @@ func asTextUnmarshaler(field reflect.Value) encoding.TextUnmarshaler {
 	return unmarshaler
 }
 
-func parseTextUnmarshalers(field reflect.Value, data []string, sf reflect.StructField) error {
+func parseTextUnmarshalers(pField *parsedField, field reflect.Value, data []string) error {
 	s := len(data)
 	elemType := field.Type().Elem()
 	slice := reflect.MakeSlice(reflect.SliceOf(elemType), s, s)
@@ -35,7 +35,7 @@ func parseTextUnmarshalers(field reflect.Value, data []string, sf reflect.Struct
 		}
 		tm := sv.Interface().(encoding.TextUnmarshaler)
 		if err := tm.UnmarshalText([]byte(v)); err != nil {
-			return newParseError(sf, err)
+			return newParseError(pField, v, err)
 		}
 		if kind == reflect.Ptr {
 			slice.Index(i).Set(sv)
@@ -0,0 +1,219 @@
+package envar
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	// *time.Location fields can be parsed on systems without a tz database
+	_ "time/tzdata"
+
+	"github.com/neumachen/errorx"
+)
+
+// DefaultTimeLayout is the layout of a time.Time field without the layout
+// tag option.
+const DefaultTimeLayout = time.RFC3339
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// timeLayouts are the layouts of the time package that can be used by name
+// in the layout tag option, e.g. layout=RFC1123
+var timeLayouts = map[string]string{
+	"Layout":      time.Layout,
+	"ANSIC":       time.ANSIC,
+	"UnixDate":    time.UnixDate,
+	"RubyDate":    time.RubyDate,
+	"RFC822":      time.RFC822,
+	"RFC822Z":     time.RFC822Z,
+	"RFC850":      time.RFC850,
+	"RFC1123":     time.RFC1123,
+	"RFC1123Z":    time.RFC1123Z,
+	"RFC3339":     time.RFC3339,
+	"RFC3339Nano": time.RFC3339Nano,
+	"Kitchen":     time.Kitchen,
+	"Stamp":       time.Stamp,
+	"StampMilli":  time.StampMilli,
+	"StampMicro":  time.StampMicro,
+	"StampNano":   time.StampNano,
+	"DateTime":    time.DateTime,
+	"DateOnly":    time.DateOnly,
+	"TimeOnly":    time.TimeOnly,
+}
+
+// timeLayout returns the layout named name, or name itself if it is not the
+// name of a layout of the time package.
+func timeLayout(name string) string {
+	if layout, ok := timeLayouts[name]; ok {
+		return layout
+	}
+	return name
+}
+
+// timeParserFunc returns the ParserFunc of a time.Time field, or of the
+// elements of a slice of time.Time, nil for any other type. The layout tag
+// option takes precedence over the ParserFunc of the ParserFuncMap, which is
+// used instead of the encoding.TextUnmarshaler implementation of time.Time.
+func (p *parsedField) timeParserFunc(parserCtx ParserCtxGetter, rType reflect.Type) ParserFunc {
+	if rType.Kind() == reflect.Ptr {
+		rType = rType.Elem()
+	}
+	if rType != timeType {
+		return nil
+	}
+	if layout, ok := p.tagOpts.getLayout(); ok {
+		return parseTime(timeLayout(layout))
+	}
+	return parserCtx.GetParserFuncMap().Get(timeType)
+}
+
+func parseTime(layout string) ParserFunc {
+	return func(v string) (interface{}, error) {
+		t, err := time.Parse(layout, strings.TrimSpace(v))
+		if err != nil {
+			return nil, errorx.New(fmt.Sprintf("unable to parse time: %v", err.Error()))
+		}
+		return t, nil
+	}
+}
+
+var locationType = reflect.TypeOf((*time.Location)(nil))
+
+// parseLocation is the ParserFunc of *time.Location. It returns the pointer
+// which is set as is, e.g. so that UTC is time.UTC, rather than copied.
+func parseLocation(v string) (interface{}, error) {
+	loc, err := time.LoadLocation(strings.TrimSpace(v))
+	if err != nil {
+		return nil, errorx.New(fmt.Sprintf("unable to parse location: %v", err.Error()))
+	}
+	return loc, nil
+}
+
+// extendedDurationUnits are the units that ParseDuration accepts on top of
+// the ones of time.ParseDuration.
+var extendedDurationUnits = map[string]time.Duration{
+	"d": 24 * time.Hour,
+	"w": 7 * 24 * time.Hour,
+}
+
+// isoDurationRegexp matches an ISO 8601 duration, years and months are
+// matched so that they can be reported.
+var isoDurationRegexp = regexp.MustCompile(
+	`^([-+]?)P(?:(\d+(?:[.,]\d+)?)Y)?(?:(\d+(?:[.,]\d+)?)M)?(?:(\d+(?:[.,]\d+)?)W)?(?:(\d+(?:[.,]\d+)?)D)?` +
+		`(?:T(?:(\d+(?:[.,]\d+)?)H)?(?:(\d+(?:[.,]\d+)?)M)?(?:(\d+(?:[.,]\d+)?)S)?)?$`,
+)
+
+// isoDurationUnits are the units of the submatches of isoDurationRegexp,
+// years and months have no fixed duration.
+var isoDurationUnits = []time.Duration{0, 0, 7 * 24 * time.Hour, 24 * time.Hour, time.Hour, time.Minute, time.Second}
+
+// ParseDuration parses a duration like time.ParseDuration does, but also
+// accepts days and weeks, e.g. 7d or 1w2d12h, where a day is 24 hours, and
+// ISO 8601 durations without years and months, e.g. P1DT2H or PT30M.
+func ParseDuration(v string) (time.Duration, error) {
+	v = strings.TrimSpace(v)
+	if strings.HasPrefix(strings.TrimLeft(v, "-+"), "P") {
+		return parseISODuration(v)
+	}
+	if !strings.ContainsAny(v, "dw") {
+		return time.ParseDuration(v)
+	}
+
+	s := v
+	neg := false
+	if s != "" && (s[0] == '-' || s[0] == '+') {
+		neg = s[0] == '-'
+		s = s[1:]
+	}
+	if s == "" {
+		return 0, errorx.New(fmt.Sprintf("invalid duration %q", v))
+	}
+
+	var d time.Duration
+	for s != "" {
+		i := strings.IndexFunc(s, func(r rune) bool { return r != '.' && (r < '0' || r > '9') })
+		if i <= 0 {
+			return 0, errorx.New(fmt.Sprintf("invalid duration %q", v))
+		}
+		j := strings.IndexFunc(s[i:], func(r rune) bool { return r == '.' || ('0' <= r && r <= '9') })
+		if j < 0 {
+			j = len(s) - i
+		}
+		num, unit := s[:i], s[i:i+j]
+		s = s[i+j:]
+
+		var part time.Duration
+		if u, ok := extendedDurationUnits[unit]; ok {
+			f, err := strconv.ParseFloat(num, 64)
+			if err != nil {
+				return 0, errorx.New(fmt.Sprintf("invalid duration %q", v))
+			}
+			if part, ok = scaleDuration(f, u); !ok {
+				return 0, errorx.New(fmt.Sprintf("invalid duration %q: out of range", v))
+			}
+		} else {
+			var err error
+			if part, err = time.ParseDuration(num + unit); err != nil {
+				return 0, errorx.New(fmt.Sprintf("invalid duration %q: %v", v, err.Error()))
+			}
+		}
+		if d > math.MaxInt64-part {
+			return 0, errorx.New(fmt.Sprintf("invalid duration %q: out of range", v))
+		}
+		d += part
+	}
+
+	if neg {
+		d = -d
+	}
+	return d, nil
+}
+
+func parseISODuration(v string) (time.Duration, error) {
+	m := isoDurationRegexp.FindStringSubmatch(v)
+	if m == nil || strings.HasSuffix(v, "T") {
+		return 0, errorx.New(fmt.Sprintf("invalid ISO 8601 duration %q", v))
+	}
+	if m[2] != "" || m[3] != "" {
+		return 0, errorx.New(fmt.Sprintf("invalid ISO 8601 duration %q: years and months are not supported", v))
+	}
+
+	var d time.Duration
+	found := false
+	for i, unit := range isoDurationUnits {
+		num := m[i+2]
+		if num == "" || unit == 0 {
+			continue
+		}
+		found = true
+		f, err := strconv.ParseFloat(strings.Replace(num, ",", ".", 1), 64)
+		if err != nil {
+			return 0, errorx.New(fmt.Sprintf("invalid ISO 8601 duration %q", v))
+		}
+		part, ok := scaleDuration(f, unit)
+		if !ok || d > math.MaxInt64-part {
+			return 0, errorx.New(fmt.Sprintf("invalid ISO 8601 duration %q: out of range", v))
+		}
+		d += part
+	}
+	if !found {
+		return 0, errorx.New(fmt.Sprintf("invalid ISO 8601 duration %q", v))
+	}
+
+	if m[1] == "-" {
+		d = -d
+	}
+	return d, nil
+}
+
+// scaleDuration returns f times unit, ok is false if it overflows.
+func scaleDuration(f float64, unit time.Duration) (time.Duration, bool) {
+	d := f * float64(unit)
+	if d >= math.MaxInt64 {
+		return 0, false
+	}
+	return time.Duration(d), true
+}
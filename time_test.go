@@ -0,0 +1,231 @@
+package envar
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type timeConfig struct {
+	Cutover  time.Time       `env:"CUTOVER"`
+	Date     time.Time       `env:"DATE,layout=DateOnly"`
+	Expires  *time.Time      `env:"EXPIRES,layout=RFC1123"`
+	Windows  []time.Time     `env:"WINDOWS,layout=15:04"`
+	Location *time.Location  `env:"LOCATION"`
+	TTL      time.Duration   `env:"TTL,validate=min=1d"`
+	Retries  []time.Duration `env:"RETRIES"`
+}
+
+func TestParse_TimeTypes(t *testing.T) {
+	eMap := EnvVarsMap{
+		"CUTOVER":  "2024-03-01T12:30:00+01:00",
+		"DATE":     "2024-03-01",
+		"EXPIRES":  "Fri, 01 Mar 2024 12:30:00 UTC",
+		"WINDOWS":  "02:00,14:30",
+		"LOCATION": "Europe/Amsterdam",
+		"TTL":      "1w2d",
+		"RETRIES":  "1s,P1DT2H,1.5d",
+	}
+
+	cfg := timeConfig{}
+	_, err := Parse(&cfg, SetEnvVarsLoaderFunc(EnvVarsMapLoaderFunc(eMap)))
+	require.NoError(t, err)
+
+	require.True(t, time.Date(2024, 3, 1, 11, 30, 0, 0, time.UTC).Equal(cfg.Cutover))
+	require.Equal(t, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), cfg.Date)
+	require.NotNil(t, cfg.Expires)
+	require.True(t, time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC).Equal(*cfg.Expires))
+	require.Len(t, cfg.Windows, 2)
+	require.Equal(t, 14, cfg.Windows[1].Hour())
+	require.NotNil(t, cfg.Location)
+	require.Equal(t, "Europe/Amsterdam", cfg.Location.String())
+	_, offset := cfg.Cutover.In(cfg.Location).Zone()
+	require.Equal(t, 3600, offset)
+	require.Equal(t, 9*24*time.Hour, cfg.TTL)
+	require.Equal(t, []time.Duration{time.Second, 26 * time.Hour, 36 * time.Hour}, cfg.Retries)
+
+	// the values are formatted with the layout of the field
+	marshaled, err := Marshal(cfg)
+	require.NoError(t, err)
+	require.Equal(t, "2024-03-01", marshaled["DATE"])
+	require.Equal(t, "02:00,14:30", marshaled["WINDOWS"])
+	require.Equal(t, "Europe/Amsterdam", marshaled["LOCATION"])
+
+	roundTrip := timeConfig{}
+	_, err = Parse(&roundTrip, SetEnvVarsLoaderFunc(EnvVarsMapLoaderFunc(marshaled)))
+	require.NoError(t, err)
+	require.True(t, cfg.Cutover.Equal(roundTrip.Cutover))
+	require.Equal(t, cfg.Date, roundTrip.Date)
+	require.Equal(t, cfg.Windows, roundTrip.Windows)
+	require.Equal(t, cfg.TTL, roundTrip.TTL)
+}
+
+func TestParse_TimeTypesErrors(t *testing.T) {
+	tests := []struct {
+		key   string
+		value string
+	}{
+		{key: "CUTOVER", value: "2024-03-01"},
+		{key: "DATE", value: "2024-03-01T12:30:00Z"},
+		{key: "LOCATION", value: "Mars/Olympus_Mons"},
+		{key: "TTL", value: "1y"},
+		{key: "TTL", value: "P1M"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key+"="+tt.value, func(t *testing.T) {
+			cfg := timeConfig{}
+			_, err := Parse(&cfg, SetEnvVarsLoaderFunc(EnvVarsMapLoaderFunc(EnvVarsMap{tt.key: tt.value})))
+			require.Error(t, err)
+
+			parseErr := &ParseError{}
+			require.ErrorAs(t, err, &parseErr)
+			require.Equal(t, tt.value, parseErr.Value())
+		})
+	}
+
+	// the min validator compares the extended duration
+	cfg := timeConfig{}
+	parserCtx, err := Parse(&cfg, SetEnvVarsLoaderFunc(EnvVarsMapLoaderFunc(EnvVarsMap{"TTL": "23h"})))
+	require.Error(t, err)
+	require.Len(t, parserCtx.GetValidationErrors()["timeConfig.TTL"], 1)
+}
+
+func TestParse_Location(t *testing.T) {
+	type config struct {
+		UTC       *time.Location   `env:"UTC"`
+		Local     *time.Location   `env:"LOCAL"`
+		Locations []*time.Location `env:"LOCATIONS"`
+	}
+
+	cfg := config{}
+	_, err := Parse(&cfg, SetEnvVarsLoaderFunc(EnvVarsMapLoaderFunc(EnvVarsMap{
+		"UTC":       "UTC",
+		"LOCAL":     "Local",
+		"LOCATIONS": "Europe/Amsterdam,UTC",
+	})))
+	require.NoError(t, err)
+
+	// the locations of time.LoadLocation are kept as is
+	require.Same(t, time.UTC, cfg.UTC)
+	require.Same(t, time.Local, cfg.Local)
+	require.Len(t, cfg.Locations, 2)
+	require.Equal(t, "Europe/Amsterdam", cfg.Locations[0].String())
+	require.Same(t, time.UTC, cfg.Locations[1])
+
+	eMap, err := Marshal(cfg)
+	require.NoError(t, err)
+	require.Equal(t, EnvVarsMap{
+		"UTC":       "UTC",
+		"LOCAL":     "Local",
+		"LOCATIONS": "Europe/Amsterdam,UTC",
+	}, eMap)
+}
+
+func TestParse_LocationMap(t *testing.T) {
+	type config struct {
+		Offices map[string]*time.Location `env:"OFFICES"`
+	}
+
+	cfg := config{}
+	_, err := Parse(&cfg, SetEnvVarsLoaderFunc(EnvVarsMapLoaderFunc(EnvVarsMap{
+		"OFFICES": "ams:Europe/Amsterdam,hq:UTC",
+	})))
+	require.NoError(t, err)
+	require.Len(t, cfg.Offices, 2)
+	require.Equal(t, "Europe/Amsterdam", cfg.Offices["ams"].String())
+	require.Same(t, time.UTC, cfg.Offices["hq"])
+
+	_, err = Parse(&config{}, SetEnvVarsLoaderFunc(EnvVarsMapLoaderFunc(EnvVarsMap{
+		"OFFICES": "mars:Mars/Olympus_Mons",
+	})))
+	require.Error(t, err)
+	parseErr := &ParseError{}
+	require.ErrorAs(t, err, &parseErr)
+	require.Equal(t, "Mars/Olympus_Mons", parseErr.Value())
+}
+
+func TestParse_LocationCustomParserFunc(t *testing.T) {
+	type config struct {
+		Location  *time.Location            `env:"LOCATION"`
+		Locations []*time.Location          `env:"LOCATIONS"`
+		Offices   map[string]*time.Location `env:"OFFICES"`
+	}
+
+	// the ParserFunc of *time.Location replaces the default one
+	cet := time.FixedZone("CET", 3600)
+	cfg := config{}
+	_, err := Parse(&cfg,
+		AddParserFunc(locationType, func(v string) (interface{}, error) {
+			if v == "CET" {
+				return cet, nil
+			}
+			return time.UTC, nil
+		}),
+		SetEnvVarsLoaderFunc(EnvVarsMapLoaderFunc(EnvVarsMap{
+			"LOCATION":  "CET",
+			"LOCATIONS": "CET,Mars/Olympus_Mons",
+			"OFFICES":   "ams:CET",
+		})),
+	)
+	require.NoError(t, err)
+	require.Same(t, cet, cfg.Location)
+	require.Len(t, cfg.Locations, 2)
+	require.Same(t, cet, cfg.Locations[0])
+	require.Same(t, time.UTC, cfg.Locations[1])
+	require.Same(t, cet, cfg.Offices["ams"])
+}
+
+func TestParse_TimeCustomParserFunc(t *testing.T) {
+	type config struct {
+		At   time.Time `env:"AT"`
+		Date time.Time `env:"DATE,layout=DateOnly"`
+	}
+
+	// a ParserFunc replaces the default layout, the layout tag option still
+	// takes precedence
+	cfg := config{}
+	_, err := Parse(&cfg,
+		AddParserFunc(timeType, parseTime(time.DateTime)),
+		SetEnvVarsLoaderFunc(EnvVarsMapLoaderFunc(EnvVarsMap{
+			"AT":   "2024-03-01 12:30:00",
+			"DATE": "2024-03-02",
+		})),
+	)
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC), cfg.At)
+	require.Equal(t, time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC), cfg.Date)
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected time.Duration
+	}{
+		{value: "0", expected: 0},
+		{value: "1h30m", expected: 90 * time.Minute},
+		{value: "7d", expected: 7 * 24 * time.Hour},
+		{value: "2w", expected: 14 * 24 * time.Hour},
+		{value: "1w2d12h30m", expected: 9*24*time.Hour + 12*time.Hour + 30*time.Minute},
+		{value: "-1.5d", expected: -36 * time.Hour},
+		{value: "P1DT2H", expected: 26 * time.Hour},
+		{value: "PT30M", expected: 30 * time.Minute},
+		{value: "PT0,5S", expected: 500 * time.Millisecond},
+		{value: "P2W", expected: 14 * 24 * time.Hour},
+		{value: "-P1D", expected: -24 * time.Hour},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			d, err := ParseDuration(tt.value)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, d)
+		})
+	}
+
+	for _, v := range []string{"", "d", "1d2", "1x", "P", "PT", "P1Y", "P1M", "P1H", "PT1D", "99999999999w"} {
+		t.Run(v, func(t *testing.T) {
+			_, err := ParseDuration(v)
+			require.Error(t, err)
+		})
+	}
+}
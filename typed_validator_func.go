@@ -0,0 +1,234 @@
+package envar
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/neumachen/errorx"
+)
+
+// TypedValidatorFunc validates the value of a field once it was converted to
+// the type of the field, defaults included. The value is never a pointer and
+// the validator is not run when the field has no value. The param is the one
+// of the rule, e.g. 1s for min=1s, and is empty if the rule has none.
+type TypedValidatorFunc func(
+	parserCtx ParserCtxAccessor,
+	parsedField ParsedFieldGetter,
+	value reflect.Value,
+	param string,
+) error
+
+type TypedValidatorFuncsMap map[string]TypedValidatorFunc
+
+func (v TypedValidatorFuncsMap) GetLength() int {
+	return len(v)
+}
+
+func (v TypedValidatorFuncsMap) Add(key string, fn TypedValidatorFunc) {
+	v[key] = fn
+}
+
+var defaultTypedValidatorsFunc = TypedValidatorFuncsMap{
+	"min":   validateMin,
+	"max":   validateMax,
+	"len":   validateLen,
+	"oneof": validateOneOf,
+}
+
+// validateBound compares a numeric value with the param converted to the type
+// of the value, e.g. a time.Duration with 1s, and the length of a string,
+// slice or map with the param. valid reports whether the result of the
+// comparison is valid, e.g. not negative for min.
+func validateBound(
+	parserCtx ParserCtxAccessor,
+	parsedField ParsedFieldGetter,
+	value reflect.Value,
+	param string,
+	valid func(cmp int) bool,
+	msg string,
+) error {
+	if hasLength(value) {
+		bound, err := strconv.Atoi(param)
+		if err != nil {
+			return newInvalidParamError(parsedField, param, err)
+		}
+		if !valid(compareInts(int64(length(value)), int64(bound))) {
+			parserCtx.AddFieldValidationError(NewValidationError(
+				parsedField,
+				fmt.Sprintf("env key: %s value length %d is %s %s", parsedField.GetEnvKey(), length(value), msg, param),
+			))
+		}
+		return nil
+	}
+
+	bound, found, err := convertValue(parserCtx, value.Type(), param)
+	if !found {
+		return errorx.New(fmt.Sprintf(
+			"validator func: %s is not supported for type %s", parsedField.GetValidateRule(), value.Type(),
+		))
+	}
+	if err != nil {
+		return newInvalidParamError(parsedField, param, err)
+	}
+
+	cmp, ok := compareNumbers(value, bound)
+	if !ok {
+		return errorx.New(fmt.Sprintf(
+			"validator func: %s is not supported for type %s", parsedField.GetValidateRule(), value.Type(),
+		))
+	}
+	if !valid(cmp) {
+		parserCtx.AddFieldValidationError(NewValidationError(
+			parsedField,
+			fmt.Sprintf(
+				"env key: %s value %s is %s %s",
+				parsedField.GetEnvKey(),
+				redactElem(parsedField, fmt.Sprint(value.Interface())),
+				msg,
+				param,
+			),
+		))
+	}
+	return nil
+}
+
+func validateMin(parserCtx ParserCtxAccessor, parsedField ParsedFieldGetter, value reflect.Value, param string) error {
+	return validateBound(parserCtx, parsedField, value, param, func(cmp int) bool {
+		return cmp >= 0
+	}, "less than")
+}
+
+func validateMax(parserCtx ParserCtxAccessor, parsedField ParsedFieldGetter, value reflect.Value, param string) error {
+	return validateBound(parserCtx, parsedField, value, param, func(cmp int) bool {
+		return cmp <= 0
+	}, "greater than")
+}
+
+func validateLen(parserCtx ParserCtxAccessor, parsedField ParsedFieldGetter, value reflect.Value, param string) error {
+	if !hasLength(value) {
+		return errorx.New(fmt.Sprintf(
+			"validator func: %s is not supported for type %s", parsedField.GetValidateRule(), value.Type(),
+		))
+	}
+	n, err := strconv.Atoi(param)
+	if err != nil {
+		return newInvalidParamError(parsedField, param, err)
+	}
+	if l := length(value); l != n {
+		parserCtx.AddFieldValidationError(NewValidationError(
+			parsedField,
+			fmt.Sprintf("env key: %s value length %d is not %d", parsedField.GetEnvKey(), l, n),
+		))
+	}
+	return nil
+}
+
+// validateOneOf compares the value, or each element of a slice, with the
+// space separated values of the param converted to the type of the value.
+func validateOneOf(parserCtx ParserCtxAccessor, parsedField ParsedFieldGetter, value reflect.Value, param string) error {
+	allowed := strings.Fields(param)
+
+	values := []reflect.Value{value}
+	if value.Kind() == reflect.Slice && !isSingleValueSlice(parserCtx, value.Type()) {
+		values = make([]reflect.Value, value.Len())
+		for i := range values {
+			values[i] = reflect.Indirect(value.Index(i))
+		}
+	}
+
+	for _, v := range values {
+		ok := false
+		for i := range allowed {
+			a, found, err := convertValue(parserCtx, v.Type(), allowed[i])
+			if !found {
+				return errorx.New(fmt.Sprintf(
+					"validator func: %s is not supported for type %s", parsedField.GetValidateRule(), v.Type(),
+				))
+			}
+			if err != nil {
+				return newInvalidParamError(parsedField, allowed[i], err)
+			}
+			if reflect.DeepEqual(a.Interface(), v.Interface()) {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			parserCtx.AddFieldValidationError(NewValidationError(
+				parsedField,
+				fmt.Sprintf(
+					"env key: %s value %s is not one of: %s",
+					parsedField.GetEnvKey(),
+					redactElem(parsedField, fmt.Sprint(v.Interface())),
+					strings.Join(allowed, ", "),
+				),
+			))
+			return nil
+		}
+	}
+	return nil
+}
+
+func newInvalidParamError(parsedField ParsedFieldGetter, param string, err error) error {
+	return errorx.New(fmt.Sprintf("validator func: %s: invalid param %q: %v", parsedField.GetValidateRule(), param, err))
+}
+
+// hasLength reports whether the min, max and len rules use the length of the
+// value.
+func hasLength(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map:
+		return true
+	}
+	return false
+}
+
+// length returns the number of characters of a string or the number of
+// elements of a slice or map.
+func length(value reflect.Value) int {
+	if value.Kind() == reflect.String {
+		return utf8.RuneCountInString(value.String())
+	}
+	return value.Len()
+}
+
+// compareNumbers compares two numbers of the same type, ok is false if they
+// are not numbers.
+func compareNumbers(a, b reflect.Value) (cmp int, ok bool) {
+	switch a.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return compareInts(a.Int(), b.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		x, y := a.Uint(), b.Uint()
+		switch {
+		case x < y:
+			return -1, true
+		case x > y:
+			return 1, true
+		}
+		return 0, true
+	case reflect.Float32, reflect.Float64:
+		x, y := a.Float(), b.Float()
+		switch {
+		case x < y:
+			return -1, true
+		case x > y:
+			return 1, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+func compareInts(x, y int64) int {
+	switch {
+	case x < y:
+		return -1
+	case x > y:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,145 @@
+package envar
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type typedValidateConfig struct {
+	Timeout   time.Duration  `env:"TIMEOUT,default=5s,validate=min=1s|max=1m"`
+	Workers   uint8          `env:"WORKERS,validate=min=1"`
+	Hosts     []string       `env:"HOSTS,validate=min=1|max=3"`
+	Weights   map[string]int `env:"WEIGHTS,validate=len=2"`
+	Levels    []int          `env:"LEVELS,validate=oneof=1 2 3"`
+	Retries   *int           `env:"RETRIES,validate=oneof=0 3 5"`
+	Name      string         `env:"NAME,default=app,validate=not_empty"`
+	Pin       Secret[int]    `env:"PIN,validate=min=1000"`
+	Threshold float32        `env:"THRESHOLD,validate=max=0.75"`
+}
+
+func TestParse_TypedValidators(t *testing.T) {
+	cfg := typedValidateConfig{}
+	_, err := Parse(&cfg, SetEnvVarsLoaderFunc(EnvVarsMapLoaderFunc(EnvVarsMap{
+		"WORKERS":   "4",
+		"HOSTS":     "a,b",
+		"WEIGHTS":   "a:1,b:2",
+		"LEVELS":    "1,3",
+		"RETRIES":   "3",
+		"NAME":      "",
+		"PIN":       "1234",
+		"THRESHOLD": "0.75",
+	})))
+	require.NoError(t, err)
+	require.Equal(t, 5*time.Second, cfg.Timeout)
+	require.Equal(t, "app", cfg.Name)
+
+	_, err = Parse(&typedValidateConfig{}, SetEnvVarsLoaderFunc(EnvVarsMapLoaderFunc(EnvVarsMap{
+		"TIMEOUT":   "500ms",
+		"WORKERS":   "0",
+		"HOSTS":     "a,b,c,d",
+		"WEIGHTS":   "a:1",
+		"LEVELS":    "1,4",
+		"RETRIES":   "1",
+		"PIN":       "12",
+		"THRESHOLD": "0.8",
+	})))
+	validationErrs := &ValidationErrors{}
+	require.ErrorAs(t, err, &validationErrs)
+
+	messages := map[string]string{}
+	for _, vErr := range validationErrs.Errors.List() {
+		messages[vErr.FieldPath] = vErr.Message
+	}
+	require.Equal(t, map[string]string{
+		"typedValidateConfig.Timeout":   "env key: TIMEOUT value 500ms is less than 1s",
+		"typedValidateConfig.Workers":   "env key: WORKERS value 0 is less than 1",
+		"typedValidateConfig.Hosts":     "env key: HOSTS value length 4 is greater than 3",
+		"typedValidateConfig.Weights":   "env key: WEIGHTS value length 1 is not 2",
+		"typedValidateConfig.Levels":    "env key: LEVELS value 4 is not one of: 1, 2, 3",
+		"typedValidateConfig.Retries":   "env key: RETRIES value 1 is not one of: 0, 3, 5",
+		"typedValidateConfig.Pin":       "env key: PIN value ****** is less than 1000",
+		"typedValidateConfig.Threshold": "env key: THRESHOLD value 0.8 is greater than 0.75",
+	}, messages)
+}
+
+func TestParse_TypedValidators_Unsupported(t *testing.T) {
+	type config struct {
+		Enabled bool `env:"ENABLED,validate=min=1"`
+		Port    int  `env:"PORT,validate=len=4"`
+	}
+
+	_, err := Parse(&config{}, SetEnvVarsLoaderFunc(EnvVarsMapLoaderFunc(EnvVarsMap{
+		"ENABLED": "true",
+		"PORT":    "8080",
+	})))
+	var parseErrs ParseErrors
+	require.ErrorAs(t, err, &parseErrs)
+	require.Len(t, parseErrs, 2)
+	require.Contains(t, parseErrs[0].Error(), "validator func: min is not supported for type bool")
+	require.Contains(t, parseErrs[1].Error(), "validator func: len is not supported for type int")
+}
+
+func TestParse_AddTypedValidatorFunc(t *testing.T) {
+	type config struct {
+		Deadline time.Duration `env:"DEADLINE,validate=whole_seconds"`
+		Missing  time.Duration `env:"MISSING,validate=whole_seconds"`
+	}
+
+	var validated []reflect.Value
+	wholeSeconds := func(parserCtx ParserCtxAccessor, parsedField ParsedFieldGetter, value reflect.Value, _ string) error {
+		validated = append(validated, value)
+		if d := value.Interface().(time.Duration); d%time.Second != 0 {
+			parserCtx.AddFieldValidationError(NewValidationError(parsedField, "not a whole number of seconds"))
+		}
+		return nil
+	}
+
+	_, err := Parse(
+		&config{},
+		AddTypedValidatorFunc("whole_seconds", wholeSeconds),
+		SetEnvVarsLoaderFunc(EnvVarsMapLoaderFunc(EnvVarsMap{"DEADLINE": "1500ms"})),
+	)
+	validationErrs := &ValidationErrors{}
+	require.ErrorAs(t, err, &validationErrs)
+	require.Equal(t, []ValidationError{{
+		FieldPath: "config.Deadline",
+		EnvKey:    "DEADLINE",
+		Rule:      "whole_seconds",
+		Message:   "not a whole number of seconds",
+	}}, validationErrs.Errors.Get("config.Deadline"))
+	// fields without a value are not validated
+	require.Len(t, validated, 1)
+}
+
+func TestParse_OneOfSensitiveSlice(t *testing.T) {
+	type config struct {
+		Keys []string `env:"KEYS,sensitive,validate=oneof=a b"`
+	}
+
+	ctx, err := Parse(&config{}, SetEnvVarsLoaderFunc(EnvVarsMapLoaderFunc(EnvVarsMap{"KEYS": "a,topsecret"})))
+	require.Error(t, err)
+	require.NotContains(t, err.Error(), "topsecret")
+	require.Equal(
+		t,
+		"env key: KEYS value ****** is not one of: a, b",
+		ctx.GetValidationErrors().Get("config.Keys")[0].Message,
+	)
+}
+
+func TestParse_BoundSensitiveValue(t *testing.T) {
+	type config struct {
+		Timeout time.Duration `env:"TIMEOUT,sensitive,validate=min=2m"`
+	}
+
+	ctx, err := Parse(&config{}, SetEnvVarsLoaderFunc(EnvVarsMapLoaderFunc(EnvVarsMap{"TIMEOUT": "60s"})))
+	require.Error(t, err)
+	require.NotContains(t, err.Error(), "1m0s")
+	require.Equal(
+		t,
+		"env key: TIMEOUT value ****** is less than 2m",
+		ctx.GetValidationErrors().Get("config.Timeout")[0].Message,
+	)
+}
@@ -0,0 +1,114 @@
+package envar
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// listParamRules are the rules whose param is a space separated list.
+var listParamRules = map[string]bool{
+	"oneof":            true,
+	"required_if":      true,
+	"required_with":    true,
+	"required_without": true,
+	"excluded_with":    true,
+	"exactly_one_of":   true,
+	"at_least_one_of":  true,
+}
+
+// NewValidationError returns a ValidationError for the field that is being
+// validated, the rule is the name of the validator that is currently running
+// and the args are its param, split on whitespace for the rules whose param is
+// a list, e.g. oneof. It is meant to be used by a ValidatorFunc together with
+// AddFieldValidationError.
+func NewValidationError(parsedField ParsedFieldGetter, message string) ValidationError {
+	vErr := ValidationError{
+		FieldPath: parsedField.GetFieldPath(),
+		EnvKey:    parsedField.GetEnvKey(),
+		Rule:      parsedField.GetValidateRule(),
+		Message:   message,
+	}
+	if param := parsedField.GetValidateParam(); param != "" {
+		vErr.Args = []string{param}
+		if listParamRules[vErr.Rule] {
+			vErr.Args = strings.Fields(param)
+		}
+	}
+	return vErr
+}
+
+// ValidationError is a single validation failure of a field.
+type ValidationError struct {
+	// FieldPath is the path of the field starting at the struct passed to
+	// Parse, e.g. Config.DB.Host
+	FieldPath string `json:"field_path"`
+	// EnvKey is the env key, prefix included, that was looked up for the
+	// field.
+	EnvKey string `json:"env_key,omitempty"`
+	// Rule is the name of the validator that failed, e.g. required
+	Rule string `json:"rule,omitempty"`
+	// Args are the arguments that were given to the rule.
+	Args []string `json:"args,omitempty"`
+	// Message describes the failure.
+	Message string `json:"message"`
+}
+
+func (e ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.FieldPath, e.Message)
+}
+
+// ValidationErrorMap holds the validation errors of each field keyed by the
+// field path.
+type ValidationErrorMap map[string][]ValidationError
+
+func (v ValidationErrorMap) HasErrors(key string) bool {
+	value, ok := v[key]
+	if !ok {
+		return false
+	}
+	return len(value) > 0
+}
+
+// Get returns the validation errors of the field found at the key.
+func (v ValidationErrorMap) Get(key string) []ValidationError {
+	return v[key]
+}
+
+func (v ValidationErrorMap) add(vErr ValidationError) {
+	v[vErr.FieldPath] = append(v[vErr.FieldPath], vErr)
+}
+
+func (v ValidationErrorMap) GetLength() int {
+	return len(v)
+}
+
+// List returns every validation error ordered by field path.
+func (v ValidationErrorMap) List() []ValidationError {
+	keys := make([]string, 0, len(v))
+	for k := range v {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	list := make([]ValidationError, 0, len(keys))
+	for i := range keys {
+		list = append(list, v[keys[i]]...)
+	}
+	return list
+}
+
+// ValidationErrors is the error returned by Parse when one or more validators
+// reported a failure.
+type ValidationErrors struct {
+	Errors ValidationErrorMap `json:"errors"`
+}
+
+func (e *ValidationErrors) Error() string {
+	list := e.Errors.List()
+	msgs := make([]string, len(list))
+	for i := range list {
+		msgs[i] = list[i].Error()
+	}
+	return fmt.Sprintf("env: validation failed: %s", strings.Join(msgs, "; "))
+}
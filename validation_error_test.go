@@ -0,0 +1,57 @@
+package envar
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidationError(t *testing.T) {
+	type database struct {
+		Host string `env:"HOST,validate=required"`
+	}
+	type config struct {
+		Primary database `env:",nested"`
+		Replica database `env:",nested"`
+		Name    string   `env:"NAME,validate=required|not_empty"`
+	}
+
+	cfg := config{}
+	_, err := Parse(&cfg, SetEnvVarsLoaderFunc(func() EnvVarsMap {
+		return EnvVarsMap{"NAME": ""}
+	}))
+	var validationErrs *ValidationErrors
+	require.ErrorAs(t, err, &validationErrs)
+
+	valErrors := validationErrs.Errors
+	require.Equal(t, 3, valErrors.GetLength())
+	require.True(t, valErrors.HasErrors("config.Primary.Host"))
+	require.True(t, valErrors.HasErrors("config.Replica.Host"))
+	require.Equal(t, []ValidationError{
+		{
+			FieldPath: "config.Name",
+			EnvKey:    "NAME",
+			Rule:      "not_empty",
+			Message:   "env key: NAME value is empty",
+		},
+	}, valErrors.Get("config.Name"))
+
+	list := valErrors.List()
+	require.Len(t, list, 3)
+	require.Equal(t, "config.Name", list[0].FieldPath)
+	require.Equal(t, "config.Primary.Host", list[1].FieldPath)
+	require.Equal(t, "required", list[1].Rule)
+	require.Equal(t, "config.Replica.Host", list[2].FieldPath)
+
+	b, err := json.Marshal(list[1])
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"field_path": "config.Primary.Host",
+		"env_key": "HOST",
+		"rule": "required",
+		"message": "env key: HOST not found"
+	}`, string(b))
+
+	require.Contains(t, validationErrs.Error(), "config.Primary.Host: env key: HOST not found")
+}
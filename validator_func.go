@@ -7,27 +7,6 @@ import (
 	"github.com/neumachen/gobag"
 )
 
-type validationErrorMap map[string][]string
-
-func (v validationErrorMap) HasErrors(key string) bool {
-	value, ok := v[key]
-	if !ok {
-		return false
-	}
-	return len(value) > 0
-}
-
-func (v validationErrorMap) add(key, value string) {
-	if !v.HasErrors(key) {
-		v[key] = make([]string, 0)
-	}
-	v[key] = append(v[key], value)
-}
-
-func (v validationErrorMap) GetLength() int {
-	return len(v)
-}
-
 // ValidatorFunc defines the signature of the function that will validate the
 // v. It is expected to return an error if it fails the validation
 type ValidatorFunc func(parserCtx ParserCtxAccessor, parsedField ParsedFieldGetter) error
@@ -37,9 +16,8 @@ func validateRequired(parserCtx ParserCtxAccessor, parsedField ParsedFieldGetter
 		return errorx.New("parsed field is nil")
 	}
 	if !parsedField.GetEnvFound() {
-		parserCtx.AddValidationError(
-			parsedField.GetStructField().Name,
-			fmt.Sprintf("env key: %s not found", parsedField.GetEnvKey()),
+		parserCtx.AddFieldValidationError(
+			NewValidationError(parsedField, fmt.Sprintf("env key: %s not found", parsedField.GetEnvKey())),
 		)
 	}
 	return nil
@@ -49,10 +27,10 @@ func validateNotEmpty(parserCtx ParserCtxAccessor, parsedField ParsedFieldGetter
 	if gobag.IsNil(parsedField) {
 		return errorx.New("parsed field is nil")
 	}
-	if gobag.StringIsEmpty(parsedField.GetEnvValue()) {
-		parserCtx.AddValidationError(
-			parsedField.GetStructField().Name,
-			fmt.Sprintf("env key: %s value is empty", parsedField.GetEnvKey()),
+	// the default is applied when the env var is empty
+	if gobag.StringIsEmpty(parsedField.GetFieldValue()) {
+		parserCtx.AddFieldValidationError(
+			NewValidationError(parsedField, fmt.Sprintf("env key: %s value is empty", parsedField.GetEnvKey())),
 		)
 	}
 	return nil